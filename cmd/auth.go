@@ -2,13 +2,22 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/aaronsrivastava/substack-cli/internal/api"
 	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/browser"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 func init() {
@@ -17,17 +26,94 @@ func init() {
 		Short: "Manage authentication",
 	}
 
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Add or update an account",
+		Long: `Add or update an account.
+
+Values come from --from-json, then the per-field flags, which override it.
+When stdin is a terminal, any field still empty is prompted for, with cookie
+input hidden. Without a terminal and without any flags, the six values are read
+as lines from stdin in prompt order.
+
+With --email, Substack emails a sign-in code and link instead; paste either one
+when asked. The session cookies and user ID are captured from the sign-in, and
+the publication is picked from your memberships unless --url is given.`,
+		Example: `  substack auth login
+  substack auth login --email you@example.com
+  substack auth login --name my-blog --url https://you.substack.com   # prompts for cookies
+  substack auth login --from-json - < creds.json`,
+		RunE: authLogin,
+	}
+	for _, f := range loginFields {
+		loginCmd.Flags().String(f.flag, "", f.usage)
+	}
+	loginCmd.Flags().String("email", "", "Sign in with a code or link emailed to this address instead of cookies")
+	loginCmd.Flags().String("from-json", "", "Read the account as an accounts.json entry from a file (- for stdin)")
+	loginCmd.Flags().Bool("no-activate", false, "Save the account without making it the active one")
+	loginCmd.Flags().Bool("no-verify", false, "Save the account without checking the session with Substack")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the selected account's session is still valid",
+		Long: `Check sessions with one authenticated request each and report the user,
+role and publication they belong to. Exits non-zero if any session is expired
+or cannot be checked.`,
+		Args: cobra.NoArgs,
+		RunE: authVerify,
+	}
+	verifyCmd.Flags().Bool("all", false, "Check every stored account")
+
+	importCmd := &cobra.Command{
+		Use:   "import-browser",
+		Short: "Add an account using the Substack cookies of a browser profile",
+		Long: `Add an account using the Substack session cookies of a local browser profile.
+
+Reads Firefox's cookies.sqlite, or Chromium's or Chrome's Cookies database on
+Linux where cookies are encrypted with the default key (v10). Cookies encrypted
+with a desktop keyring (v11) cannot be read. Sign in to substack.com in the
+browser first. When it is signed in to several publications, cookies set on
+the --url host win over substack.com's, which win over other publications'.
+The account name defaults to the publication's subdomain.`,
+		Example: `  substack auth import-browser --browser firefox --url https://you.substack.com
+  substack auth import-browser --browser chromium --profile ~/.config/chromium/Work --url https://you.substack.com`,
+		Args: cobra.NoArgs,
+		RunE: authImportBrowser,
+	}
+	importCmd.Flags().String("browser", browser.Firefox, "Browser to read: "+strings.Join(browser.Browsers, ", "))
+	importCmd.Flags().String("profile", "", "Profile directory or cookie database (default: the default profile)")
+	for _, f := range identityFields {
+		importCmd.Flags().String(f.flag, "", f.usage)
+	}
+	importCmd.Flags().Bool("no-activate", false, "Save the account without making it the active one")
+	importCmd.Flags().Bool("no-verify", false, "Save the account without checking the session with Substack")
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move stored cookies to another secret backend",
+		Long: `Move the cookies of every stored account to another secret backend and
+remove them from the old one.
+
+Backends:
+  keyring    the system keyring (Secret Service on Linux, Keychain, Credential Manager)
+  file       secrets.enc next to accounts.json, encrypted with a passphrase
+             (prompted for, or from ` + auth.EnvPassphrase + `)
+  plaintext  accounts.json itself`,
+		Args: cobra.NoArgs,
+		RunE: authMigrate,
+	}
+	migrateCmd.Flags().String("to", "", "Backend to move cookies to: "+strings.Join(auth.Backends(), ", "))
+
 	authCmd.AddCommand(
-		&cobra.Command{
-			Use:   "login",
-			Short: "Add or update an account",
-			RunE:  authLogin,
-		},
+		loginCmd,
 		&cobra.Command{
 			Use:   "status",
 			Short: "Show active account",
 			RunE:  authStatus,
 		},
+		importCmd,
+		verifyCmd,
+		migrateCmd,
 		&cobra.Command{
 			Use:   "list",
 			Short: "List all accounts",
@@ -50,102 +136,587 @@ func init() {
 	rootCmd.AddCommand(authCmd)
 }
 
-func prompt(scanner *bufio.Scanner, label string) string {
-	fmt.Fprintf(os.Stdout, "%s: ", label)
-	scanner.Scan()
-	return strings.TrimSpace(scanner.Text())
+// loginField ties an account field to its auth login flag and prompt.
+type loginField struct {
+	flag   string
+	usage  string
+	prompt string
+	secret bool
+	value  func(*model.Account) *string
+}
+
+var loginFields = []loginField{
+	{"name", "Account name", "Account name (e.g. my-blog)", false,
+		func(a *model.Account) *string { return &a.Name }},
+	{"url", "Publication URL", "Publication URL (e.g. https://you.substack.com)", false,
+		func(a *model.Account) *string { return &a.PublicationURL }},
+	{"user-id", "User ID", "User ID", false,
+		func(a *model.Account) *string { return &a.UserID }},
+	{"sid", "connect.sid cookie", "SID (connect.sid cookie)", true,
+		func(a *model.Account) *string { return &a.SID }},
+	{"substack-sid", "substack.sid cookie", "substack.sid cookie", true,
+		func(a *model.Account) *string { return &a.SubstackSID }},
+	{"substack-lli", "substack.lli cookie", "substack.lli cookie", true,
+		func(a *model.Account) *string { return &a.SubstackLLI }},
+}
+
+// prompt reads one value from stdin, without echo when hidden is set.
+func prompt(scanner *bufio.Scanner, label string, hidden bool) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	if hidden {
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", label, err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return "", fmt.Errorf("reading %s: %w", label, err)
+		}
+		return "", fmt.Errorf("reading %s: unexpected end of input", label)
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+// readLoginAccount assembles the account for auth login from --from-json,
+// the field flags and, where stdin allows it, prompts for what is missing.
+func readLoginAccount(cmd *cobra.Command) (model.Account, error) {
+	var acct model.Account
+	fromJSON, _ := cmd.Flags().GetString("from-json")
+	if fromJSON != "" {
+		if err := readAccountJSON(fromJSON, &acct); err != nil {
+			return acct, err
+		}
+	}
+	given := fromJSON != ""
+	for _, f := range loginFields {
+		if cmd.Flags().Changed(f.flag) {
+			*f.value(&acct), _ = cmd.Flags().GetString(f.flag)
+			given = true
+		}
+	}
+
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	if fromJSON == "-" || (given && !interactive) {
+		return acct, nil
+	}
+	return acct, promptMissing(&acct, loginFields, interactive)
+}
+
+// promptMissing prompts on stdin for each of the fields still empty in acct,
+// hiding secret ones on a terminal.
+func promptMissing(acct *model.Account, fields []loginField, interactive bool) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	for _, f := range fields {
+		field := f.value(acct)
+		if *field != "" {
+			continue
+		}
+		v, err := prompt(scanner, f.prompt, f.secret && interactive)
+		if err != nil {
+			return err
+		}
+		*field = v
+	}
+	return nil
+}
+
+func readAccountJSON(path string, acct *model.Account) error {
+	r := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(acct); err != nil {
+		return usageError{fmt.Errorf("--from-json: %w", err)}
+	}
+	return nil
+}
+
+func authLogin(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	var acct model.Account
+	if email, _ := cmd.Flags().GetString("email"); email != "" {
+		if cmd.Flags().Changed("from-json") {
+			return usageError{errors.New("--email and --from-json cannot be combined")}
+		}
+		acct, err = emailLoginAccount(cmd, p, email)
+	} else {
+		acct, err = readLoginAccount(cmd)
+	}
+	if err != nil {
+		return err
+	}
+	return saveLogin(cmd, p, acct)
 }
 
-func authLogin(_ *cobra.Command, _ []string) error {
+// emailLoginAccount signs in with the code or link Substack emails and
+// returns the account for the chosen publication. --name, --url and
+// --user-id override what the sign-in resolves.
+func emailLoginAccount(cmd *cobra.Command, p *output.Printer, email string) (model.Account, error) {
+	ctx := cmd.Context()
+	login, err := api.NewEmailLogin(email)
+	if err != nil {
+		return model.Account{}, err
+	}
+	if requestErr := login.Request(ctx); requestErr != nil {
+		return model.Account{}, fmt.Errorf("requesting sign-in email: %w", requestErr)
+	}
+	progress(p, "Sent a sign-in email to %s.", email)
 	scanner := bufio.NewScanner(os.Stdin)
+	code, err := prompt(scanner, "Code or sign-in link from the email", false)
+	if err != nil {
+		return model.Account{}, err
+	}
+	if completeErr := login.Complete(ctx, code); completeErr != nil {
+		return model.Account{}, fmt.Errorf("signing in: %w", completeErr)
+	}
+	acct, err := login.Account()
+	if err != nil {
+		return acct, err
+	}
+	profile, err := login.Profile(ctx)
+	if err != nil {
+		return acct, err
+	}
+	acct.UserID = strconv.Itoa(profile.ID)
+	for _, f := range identityFields {
+		if cmd.Flags().Changed(f.flag) {
+			*f.value(&acct), _ = cmd.Flags().GetString(f.flag)
+		}
+	}
+	if acct.PublicationURL == "" {
+		pub, chooseErr := choosePublication(scanner, profile)
+		if chooseErr != nil {
+			return acct, chooseErr
+		}
+		acct.PublicationURL = api.PublicationURL(pub)
+	}
+	if acct.Name == "" {
+		acct.Name = defaultAccountName(acct.PublicationURL)
+	}
+	return acct, nil
+}
+
+// choosePublication picks the publication to log in to from the user's
+// memberships, asking when there is more than one.
+func choosePublication(scanner *bufio.Scanner, profile *model.UserProfile) (model.PublicationSummary, error) {
+	members := profile.PublicationUsers
+	switch len(members) {
+	case 0:
+		return model.PublicationSummary{}, fmt.Errorf("%s is not a member of any publication; pass --url", profile.Name)
+	case 1:
+		return members[0].Publication, nil
+	}
+	for i, m := range members {
+		fmt.Fprintf(os.Stderr, "  %d) %s (%s, %s)\n", i+1, m.Publication.Name, api.PublicationURL(m.Publication), m.Role)
+	}
+	answer, err := prompt(scanner, fmt.Sprintf("Publication [1-%d]", len(members)), false)
+	if err != nil {
+		return model.PublicationSummary{}, err
+	}
+	n, convErr := strconv.Atoi(answer)
+	if convErr != nil || n < 1 || n > len(members) {
+		return model.PublicationSummary{}, usageError{fmt.Errorf("no publication %q; pass --url to choose", answer)}
+	}
+	return members[n-1].Publication, nil
+}
 
-	name := prompt(scanner, "Account name (e.g. my-blog)")
-	pubURL := prompt(scanner, "Publication URL (e.g. https://you.substack.com)")
-	userID := prompt(scanner, "User ID")
-	sid := prompt(scanner, "SID (connect.sid cookie)")
-	substackSID := prompt(scanner, "substack.sid cookie")
-	substackLLI := prompt(scanner, "substack.lli cookie")
+// saveLogin validates, verifies unless --no-verify, and stores a new or
+// updated account, activating it unless --no-activate.
+func saveLogin(cmd *cobra.Command, p *output.Printer, acct model.Account) error {
+	auth.NormalizeAccount(&acct)
+	if validateErr := auth.ValidateAccount(acct); validateErr != nil {
+		return usageError{validateErr}
+	}
 
-	acct := model.Account{
-		Name:           name,
-		PublicationURL: pubURL,
-		UserID:         userID,
-		SID:            sid,
-		SubstackSID:    substackSID,
-		SubstackLLI:    substackLLI,
+	var session sessionInfo
+	if noVerify, _ := cmd.Flags().GetBool("no-verify"); !noVerify {
+		progress(p, "Verifying session for %s...", acct.PublicationURL)
+		session = verifySession(cmd, &acct, nil)
+		if session.err != nil {
+			return fmt.Errorf("verifying credentials for %s: %w", acct.Name, session.err)
+		}
+		if acct.UserID == "" {
+			acct.UserID = strconv.Itoa(session.UserID)
+		}
 	}
 
-	store, err := auth.Load()
+	noActivate, _ := cmd.Flags().GetBool("no-activate")
+	var store *model.AccountStore
+	accounts, err := accountStore()
 	if err != nil {
 		return err
 	}
-	auth.AddAccount(store, acct)
-	store.Active = name
-	if saveErr := auth.Save(store); saveErr != nil {
-		return saveErr
+	err = accounts.Update(func(s *model.AccountStore) error {
+		if len(s.Accounts) == 0 && s.SecretBackend == "" {
+			backend, backendErr := auth.DefaultBackend()
+			if backendErr != nil {
+				return usageError{backendErr}
+			}
+			s.SecretBackend = backend
+		}
+		auth.AddAccount(s, acct)
+		if !noActivate {
+			s.Active = acct.Name
+		}
+		store = s
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	fmt.Fprintf(os.Stdout, "Logged in as %s (active)\n", name)
-	return nil
+	msg := fmt.Sprintf("Logged in as %s (active)", acct.Name)
+	if store.Active != acct.Name {
+		msg = fmt.Sprintf("Saved account %s (active account is still %s)", acct.Name, store.Active)
+	}
+	if session.Status == sessionOK {
+		msg += fmt.Sprintf("\nSigned in as %s (%s) on %s", session.Name, session.Role, session.Publication)
+	}
+	return p.Result(newAccountInfo(acct, store), resultView, "%s", msg)
 }
 
-func authStatus(_ *cobra.Command, _ []string) error {
-	store, err := auth.Load()
+// identityFields are the login fields other than cookies, for logins that
+// get the cookies elsewhere.
+var identityFields = loginFields[:3]
+
+func authImportBrowser(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
 	if err != nil {
 		return err
 	}
-	acct, err := auth.GetActive(store)
+	name, _ := cmd.Flags().GetString("browser")
+	if !slices.Contains(browser.Browsers, name) {
+		return usageError{fmt.Errorf("--browser must be one of %s", strings.Join(browser.Browsers, ", "))}
+	}
+	profile, _ := cmd.Flags().GetString("profile")
+
+	// The publication comes first, so the cookies can be picked for its host
+	// when the browser is signed in to several.
+	var acct model.Account
+	for _, f := range identityFields {
+		*f.value(&acct), _ = cmd.Flags().GetString(f.flag)
+	}
+	if acct.Name == "" {
+		acct.Name = defaultAccountName(acct.PublicationURL)
+	}
+	if interactive := term.IsTerminal(int(os.Stdin.Fd())); interactive {
+		if promptErr := promptMissing(&acct, identityFields[:2], interactive); promptErr != nil {
+			return promptErr
+		}
+		if acct.Name == "" {
+			acct.Name = defaultAccountName(acct.PublicationURL)
+		}
+	}
+
+	cookies, err := browser.Cookies(name, profile, publicationHost(acct.PublicationURL))
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "Active: %s (%s)\n", acct.Name, acct.PublicationURL)
-	return nil
+	found := make([]string, 0, len(cookies))
+	for _, c := range browser.CookieNames {
+		if _, ok := cookies[c]; ok {
+			found = append(found, c)
+		}
+	}
+	progress(p, "Found %s in %s", strings.Join(found, ", "), name)
+	acct.SID = cookies["connect.sid"]
+	acct.SubstackSID = cookies["substack.sid"]
+	acct.SubstackLLI = cookies["substack.lli"]
+	return saveLogin(cmd, p, acct)
 }
 
-func authList(_ *cobra.Command, _ []string) error {
-	store, err := auth.Load()
+// defaultAccountName names an account after its publication: the subdomain
+// of a substack.com URL, otherwise the host.
+func defaultAccountName(publicationURL string) string {
+	host := publicationHost(publicationURL)
+	if host == "" {
+		return ""
+	}
+	if sub, ok := strings.CutSuffix(host, "."+browser.Domain); ok {
+		return sub
+	}
+	return host
+}
+
+// sharedStore is the account store, opened on first use so that --config-dir
+// is known by then.
+var sharedStore *auth.Store
+
+// accountStore returns the account store of this invocation. Sharing one
+// means the passphrase of the encrypted secrets file is asked for at most once.
+func accountStore() (*auth.Store, error) {
+	if sharedStore == nil {
+		store, err := auth.DefaultStore(configDir)
+		if err != nil {
+			return nil, err
+		}
+		store.Prompt = promptPassphrase
+		store.Warn = func(msg string) { fmt.Fprintf(os.Stderr, "Warning: %s\n", msg) }
+		sharedStore = store
+	}
+	return sharedStore, nil
+}
+
+// publicationHost returns the host name of a publication URL, or "" when it
+// has none.
+func publicationHost(publicationURL string) string {
+	u, err := url.Parse(publicationURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// promptPassphrase reads the encrypted secrets file's passphrase from the
+// terminal, twice when a new file is being created.
+func promptPassphrase(confirm bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("secrets are encrypted; set %s or run from a terminal", auth.EnvPassphrase)
+	}
+	read := func(label string) (string, error) {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	pass, err := read("Passphrase for secrets.enc")
+	if err != nil || !confirm {
+		return pass, err
+	}
+	again, err := read("Repeat passphrase")
+	if err != nil {
+		return "", err
+	}
+	if again != pass {
+		return "", errors.New("passphrases do not match")
+	}
+	return pass, nil
+}
+
+func authMigrate(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
 	if err != nil {
 		return err
 	}
-	if len(store.Accounts) == 0 {
-		fmt.Fprintln(os.Stdout, "No accounts configured. Run 'substack auth login'.")
-		return nil
+	to, _ := cmd.Flags().GetString("to")
+	if to == "" {
+		return usageError{errors.New("--to is required")}
+	}
+	if validErr := auth.ValidBackend(to); validErr != nil {
+		return usageError{validErr}
+	}
+	accounts, err := accountStore()
+	if err != nil {
+		return err
 	}
-	for _, a := range store.Accounts {
-		marker := "  "
-		if a.Name == store.Active {
-			marker = "* "
+	accounts.Warn = nil // migrating is what the plaintext warning asks for
+	from, err := accounts.Migrate(to)
+	if err != nil {
+		return err
+	}
+	result := actionResult{Name: to, Status: "migrated"}
+	if from == to {
+		result.Status = "unchanged"
+		return p.Result(result, resultView, "Cookies are already stored in %s", to)
+	}
+	return p.Result(result, resultView, "Moved cookies from %s to %s", from, to)
+}
+
+// Session states reported by auth verify.
+const (
+	sessionOK      = "ok"
+	sessionExpired = "expired"
+	sessionError   = "error"
+)
+
+// sessionInfo is the outcome of checking one account's session.
+type sessionInfo struct {
+	Account     string `json:"account"`
+	Status      string `json:"status"`
+	UserID      int    `json:"user_id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Role        string `json:"role,omitempty"`
+	Publication string `json:"publication,omitempty"`
+	Error       string `json:"error,omitempty"`
+	err         error
+}
+
+// verifySession makes one authenticated request as acct. Rejected cookies
+// count as expired; anything else that fails is reported as an error.
+// Cookies Substack rotates meanwhile are updated in acct and passed to
+// onRotate when set.
+func verifySession(cmd *cobra.Command, acct *model.Account, onRotate func(model.Account)) sessionInfo {
+	info := sessionInfo{Account: acct.Name, Publication: acct.PublicationURL}
+	client, err := newClientWith(cmd, acct)
+	if err == nil {
+		client.OnCookiesRotated = onRotate
+		var user *model.PublicationUser
+		if user, err = client.Whoami(cmd.Context()); err == nil {
+			info.Status = sessionOK
+			info.UserID, info.Name, info.Role = user.ID, user.Name, user.Role
+			if user.Publication != nil && user.Publication.Name != "" {
+				info.Publication = user.Publication.Name
+			}
+			return info
 		}
-		fmt.Fprintf(os.Stdout, "%s%s (%s)\n", marker, a.Name, a.PublicationURL)
 	}
-	return nil
+	info.Status = sessionError
+	if api.IsUnauthorized(err) || api.IsForbidden(err) {
+		info.Status = sessionExpired
+	}
+	info.Error, info.err = err.Error(), err
+	return info
 }
 
-func authSwitch(_ *cobra.Command, args []string) error {
-	store, err := auth.Load()
+func authVerify(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	s, err := accountStore()
 	if err != nil {
 		return err
 	}
-	if switchErr := auth.SwitchAccount(store, args[0]); switchErr != nil {
-		return switchErr
+	var accounts []model.Account
+	onRotate := rotationHook(accountName(cmd))
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		onRotate = saveRotatedCookies
+		store, loadErr := s.Load()
+		if loadErr != nil {
+			return loadErr
+		}
+		accounts = store.Accounts
+	} else {
+		acct, resolveErr := s.Resolve(accountName(cmd))
+		if resolveErr != nil {
+			return resolveErr
+		}
+		accounts = []model.Account{*acct}
+	}
+
+	sessions := make([]sessionInfo, len(accounts))
+	var failed []error
+	for i := range accounts {
+		sessions[i] = verifySession(cmd, &accounts[i], onRotate)
+		if sessions[i].err != nil {
+			failed = append(failed, sessions[i].err)
+		}
+	}
+	if err := p.List(sessions, sessionsView); err != nil {
+		return err
 	}
-	if saveErr := auth.Save(store); saveErr != nil {
-		return saveErr
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d sessions could not be verified: %w", len(failed), len(sessions), failed[0])
 	}
-	fmt.Fprintf(os.Stdout, "Switched to %s\n", args[0])
 	return nil
 }
 
-func authRemove(_ *cobra.Command, args []string) error {
-	store, err := auth.Load()
+// accountInfo is an account as shown to users: never with its cookies.
+type accountInfo struct {
+	Name           string `json:"name"`
+	PublicationURL string `json:"publication_url"`
+	UserID         string `json:"user_id"`
+	Active         bool   `json:"active"`
+}
+
+func newAccountInfo(a model.Account, store *model.AccountStore) accountInfo {
+	return accountInfo{Name: a.Name, PublicationURL: a.PublicationURL, UserID: a.UserID, Active: a.Name == store.Active}
+}
+
+func authStatus(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
 	if err != nil {
 		return err
 	}
-	if removeErr := auth.RemoveAccount(store, args[0]); removeErr != nil {
-		return removeErr
+	accounts, err := accountStore()
+	if err != nil {
+		return err
 	}
-	if saveErr := auth.Save(store); saveErr != nil {
-		return saveErr
+	store, err := accounts.Load()
+	if err != nil {
+		return err
 	}
-	fmt.Fprintf(os.Stdout, "Removed %s\n", args[0])
-	return nil
+	acct, err := accounts.Resolve(accountName(cmd))
+	if err != nil {
+		return err
+	}
+	if p.Human() {
+		label := "Active"
+		if acct.Name != store.Active {
+			label = "Using"
+		}
+		fmt.Fprintf(os.Stdout, "%s: %s (%s)\n", label, acct.Name, acct.PublicationURL)
+		return nil
+	}
+	return p.Object(newAccountInfo(*acct, store), resultView)
+}
+
+func authList(cmd *cobra.Command, _ []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	s, err := accountStore()
+	if err != nil {
+		return err
+	}
+	store, err := s.Load()
+	if err != nil {
+		return err
+	}
+	accounts := make([]accountInfo, len(store.Accounts))
+	for i, a := range store.Accounts {
+		accounts[i] = newAccountInfo(a, store)
+	}
+	return p.List(accounts, accountsView)
+}
+
+func authSwitch(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	accounts, err := accountStore()
+	if err != nil {
+		return err
+	}
+	err = accounts.SetActive(args[0])
+	if err != nil {
+		return err
+	}
+	return p.Result(actionResult{Name: args[0], Status: "active"}, resultView, "Switched to %s", args[0])
+}
+
+func authRemove(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	accounts, err := accountStore()
+	if err != nil {
+		return err
+	}
+	err = accounts.Update(func(store *model.AccountStore) error {
+		return auth.RemoveAccount(store, args[0])
+	})
+	if err != nil {
+		return err
+	}
+	if dropErr := dropAccountConfig(args[0]); dropErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not remove the config overrides of account %s: %v\n", args[0], dropErr)
+	}
+	return p.Result(actionResult{Name: args[0], Status: "removed"}, resultView, "Removed %s", args[0])
 }
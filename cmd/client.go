@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// newClient builds an API client for the account chosen by accountName, with
+// the request timeout and retry limit from flags or the config.
+func newClient(cmd *cobra.Command) (*api.Client, error) {
+	store, err := accountStore()
+	if err != nil {
+		return nil, err
+	}
+	name := accountName(cmd)
+	client, err := api.NewClientFor(store, name)
+	if err != nil {
+		return nil, err
+	}
+	client.OnCookiesRotated = rotationHook(name)
+	return client, configureClient(cmd, client)
+}
+
+// rotationHook returns saveRotatedCookies when the account selected by name
+// (see auth.Store.Resolve) comes from the store, and nil for credentials from the
+// environment, which are never written anywhere.
+func rotationHook(name string) func(model.Account) {
+	if _, ok, _ := auth.FromEnv(); name == "" && ok {
+		return nil
+	}
+	return saveRotatedCookies
+}
+
+// saveRotatedCookies writes cookies Substack replaced back to the account
+// store, so long-running automation keeps a live session. Failing to save
+// only warns: the current command still has the new values.
+func saveRotatedCookies(acct model.Account) {
+	store, err := accountStore()
+	if err == nil {
+		err = store.UpdateCookies(acct)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save refreshed cookies for account %s: %v\n", acct.Name, err)
+	}
+}
+
+// newClientWith is newClient for an account that need not be the selected
+// or a stored one.
+func newClientWith(cmd *cobra.Command, acct *model.Account) (*api.Client, error) {
+	client := api.NewClientWith(acct)
+	return client, configureClient(cmd, client)
+}
+
+// configureClient applies the request timeout and retry limit from flags or
+// the config.
+func configureClient(cmd *cobra.Command, client *api.Client) error {
+	timeout, err := resolveTimeout(cmd, "request-timeout", func(cfg *model.Config) string {
+		return cfg.RequestTimeout
+	}, api.DefaultRequestTimeout)
+	if err != nil {
+		return err
+	}
+	client.HTTP.Timeout = timeout
+	attempts, err := maxAttempts(cmd)
+	if err != nil {
+		return err
+	}
+	if attempts > 0 {
+		client.Retry.MaxAttempts = attempts
+	}
+	return nil
+}
+
+// maxAttempts returns --max-attempts when set, otherwise the config value.
+// Zero means the client default.
+func maxAttempts(cmd *cobra.Command) (int, error) {
+	if cmd.Flags().Changed("max-attempts") {
+		n, _ := cmd.Flags().GetInt("max-attempts")
+		if n < 1 {
+			return 0, errors.New("--max-attempts must be at least 1")
+		}
+		return n, nil
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg.MaxAttempts, nil
+}
+
+// accountEnv names the environment variable that selects an account when
+// --account is not given.
+const accountEnv = "SUBSTACK_ACCOUNT"
+
+// accountName returns the account selected for this invocation by --account
+// or SUBSTACK_ACCOUNT, or "" for the active account.
+func accountName(cmd *cobra.Command) string {
+	if name, _ := cmd.Flags().GetString("account"); name != "" {
+		return name
+	}
+	return os.Getenv(accountEnv)
+}
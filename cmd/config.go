@@ -3,15 +3,22 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
+	"time"
 
 	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
+const configKeys = "send_email, audience, section, output_format, timezone, timeout, request_timeout, max_attempts"
+
 func init() {
 	configCmd := &cobra.Command{
 		Use:   "config",
@@ -21,14 +28,24 @@ func init() {
 	configCmd.AddCommand(
 		&cobra.Command{
 			Use:   "show",
-			Short: "Show current config",
+			Short: "Show the config in effect for the selected account",
 			RunE:  configShow,
 		},
 		&cobra.Command{
 			Use:   "set <key> <value>",
-			Short: "Set a config value (send_email, audience, section, output_format)",
-			Args:  cobra.ExactArgs(2),
-			RunE:  configSet,
+			Short: "Set a config value (" + configKeys + ")",
+			Long: `Set a config value (` + configKeys + `).
+
+With --account, the value only applies to commands run against that account
+and overrides the global value.`,
+			Args: cobra.ExactArgs(2),
+			RunE: configSet,
+		},
+		&cobra.Command{
+			Use:   "unset <key>",
+			Short: "Reset a config value to its default, or with --account drop that account's override",
+			Args:  cobra.ExactArgs(1),
+			RunE:  configUnset,
 		},
 	)
 
@@ -36,14 +53,153 @@ func init() {
 }
 
 func configPath() (string, error) {
-	dir, err := auth.ConfigDir()
+	dir, err := auth.ConfigDir(configDir)
 	if err != nil {
 		return "", err
 	}
 	return filepath.Join(dir, "config.json"), nil
 }
 
-func loadConfig() (*model.Config, error) {
+// configMigrations upgrade config.json from each schema version to the next;
+// the current version is their count. Version 1 only adds the field.
+// Version 2 adds per-account overrides; the bump stops older releases, which
+// would drop them on the next save, from rewriting the file.
+var configMigrations = []filestore.Migration{
+	func(map[string]any) error { return nil },
+	func(map[string]any) error { return nil },
+}
+
+// configDefaults are the values 'config unset' restores.
+var configDefaults = map[string]string{
+	"send_email":      "false",
+	"audience":        "everyone",
+	"section":         "",
+	"output_format":   output.Table,
+	"timezone":        "",
+	"timeout":         "",
+	"request_timeout": "",
+	"max_attempts":    "0",
+}
+
+// configKey is the context key under which beforeRun keeps the config, so it
+// is read once per invocation.
+type configKey struct{}
+
+// loadConfig returns the config in effect for the account the command
+// targets: the global settings with that account's overrides applied. Each
+// call returns its own copy.
+func loadConfig(cmd *cobra.Command) (*model.Config, error) {
+	if ctx := cmd.Context(); ctx != nil {
+		if loaded, ok := ctx.Value(configKey{}).(*model.Config); ok {
+			cfg := *loaded
+			return &cfg, nil
+		}
+	}
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := accountName(cmd)
+	// Invalid credentials from the environment mean no overrides rather than
+	// an error: every command needs its config, including the ones that fix
+	// the setup, and newClient reports them where they matter.
+	if _, _, envErr := auth.FromEnv(); name == "" && len(cfg.Accounts) > 0 && envErr == nil {
+		store, storeErr := accountStore()
+		if storeErr != nil {
+			return nil, storeErr
+		}
+		if name, err = store.ResolveName(""); err != nil {
+			return nil, err
+		}
+	}
+	if applyErr := applyAccountConfig(cfg, name); applyErr != nil {
+		return nil, applyErr
+	}
+	return cfg, nil
+}
+
+// applyAccountConfig applies the overrides for account name and drops the
+// overrides of every account from cfg.
+func applyAccountConfig(cfg *model.Config, name string) error {
+	overrides := cfg.Accounts[name]
+	cfg.Accounts = nil
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if err := setConfigKey(cfg, key, overrides[key]); err != nil {
+			return fmt.Errorf("config for account %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setAccountConfig stores value as account's override for key. The value is
+// checked against a scratch copy of cfg; it is applied on load.
+func setAccountConfig(cfg *model.Config, account, key, value string) error {
+	scratch := *cfg
+	if err := setConfigKey(&scratch, key, value); err != nil {
+		return err
+	}
+	if cfg.Accounts == nil {
+		cfg.Accounts = map[string]map[string]string{}
+	}
+	if cfg.Accounts[account] == nil {
+		cfg.Accounts[account] = map[string]string{}
+	}
+	cfg.Accounts[account][key] = value
+	return nil
+}
+
+// unsetAccountConfig drops account's override for key, so the global value
+// applies to it again.
+func unsetAccountConfig(cfg *model.Config, account, key string) error {
+	if _, set := cfg.Accounts[account][key]; !set {
+		return fmt.Errorf("account %s has no override for %s", account, key)
+	}
+	delete(cfg.Accounts[account], key)
+	if len(cfg.Accounts[account]) == 0 {
+		delete(cfg.Accounts, account)
+	}
+	return nil
+}
+
+// checkAccount returns a usage error unless name is a stored account or
+// names credentials from the environment.
+func checkAccount(name string) error {
+	if name == auth.EnvAccountName {
+		return nil
+	}
+	if acct, ok, _ := auth.FromEnv(); ok && acct.Name == name {
+		return nil
+	}
+	store, err := accountStore()
+	if err != nil {
+		return err
+	}
+	names, err := store.Names()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(names, name) {
+		return usageError{fmt.Errorf("account %q not found; see 'substack auth list'", name)}
+	}
+	return nil
+}
+
+// dropAccountConfig removes the overrides of a removed account. The config
+// file is left alone when there are none.
+func dropAccountConfig(name string) error {
+	cfg, err := readConfig()
+	if err != nil || cfg.Accounts[name] == nil {
+		return err
+	}
+	_, err = updateConfig(func(cfg *model.Config) error {
+		delete(cfg.Accounts, name)
+		return nil
+	})
+	return err
+}
+
+// readConfig returns config.json as stored, with defaults filled in.
+func readConfig() (*model.Config, error) {
 	path, err := configPath()
 	if err != nil {
 		return nil, err
@@ -51,10 +207,14 @@ func loadConfig() (*model.Config, error) {
 	data, readErr := os.ReadFile(path)
 	if readErr != nil {
 		if os.IsNotExist(readErr) {
-			return &model.Config{Audience: "everyone", OutputFormat: "text"}, nil
+			return &model.Config{Version: len(configMigrations), Audience: "everyone", OutputFormat: output.Table}, nil
 		}
 		return nil, readErr
 	}
+	data, upgradeErr := filestore.Upgrade(data, configMigrations)
+	if upgradeErr != nil {
+		return nil, fmt.Errorf("%s: %w", path, upgradeErr)
+	}
 	var cfg model.Config
 	if unmarshalErr := json.Unmarshal(data, &cfg); unmarshalErr != nil {
 		return nil, unmarshalErr
@@ -63,8 +223,8 @@ func loadConfig() (*model.Config, error) {
 	if cfg.Audience == "" {
 		cfg.Audience = "everyone"
 	}
-	if cfg.OutputFormat == "" {
-		cfg.OutputFormat = "text"
+	if cfg.OutputFormat == "" || cfg.OutputFormat == "text" {
+		cfg.OutputFormat = output.Table
 	}
 	return &cfg, nil
 }
@@ -75,10 +235,36 @@ func validAudience(s string) bool {
 	return slices.Contains(validAudiences, s)
 }
 
-var validOutputFormats = []string{"text", "json"}
-
 func validOutputFormat(s string) bool {
-	return slices.Contains(validOutputFormats, s)
+	return slices.Contains(output.Formats, s)
+}
+
+// configLocation returns the configured timezone for interpreting dates
+// without an explicit offset, falling back to the system zone.
+func configLocation(cfg *model.Config) (*time.Location, error) {
+	if cfg.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q in config: %w", cfg.Timezone, err)
+	}
+	return loc, nil
+}
+
+// parseTimeout parses a timeout setting. "0" and "" disable the timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative timeout: %s", s)
+	}
+	return d, nil
 }
 
 func saveConfig(cfg *model.Config) error {
@@ -86,52 +272,156 @@ func saveConfig(cfg *model.Config) error {
 	if err != nil {
 		return err
 	}
-	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0700); mkdirErr != nil {
-		return mkdirErr
-	}
+	cfg.Version = len(configMigrations)
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0600)
+	return filestore.WriteAtomic(path, data, 0600)
+}
+
+// updateConfig loads the config, applies fn and saves the result while
+// holding the config file's lock. Nothing is saved when fn fails.
+func updateConfig(fn func(*model.Config) error) (*model.Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := filestore.Lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	if fnErr := fn(cfg); fnErr != nil {
+		return nil, fnErr
+	}
+	return cfg, saveConfig(cfg)
 }
 
-func configShow(_ *cobra.Command, _ []string) error {
-	cfg, err := loadConfig()
+func configShow(cmd *cobra.Command, _ []string) error {
+	if name := accountName(cmd); name != "" {
+		if checkErr := checkAccount(name); checkErr != nil {
+			return checkErr
+		}
+	}
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "send_email:    %v\naudience:      %s\nsection:       %s\noutput_format: %s\n",
-		cfg.SendEmail, cfg.Audience, cfg.Section, cfg.OutputFormat)
-	return nil
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	return p.Object(cfg, output.View{RawKeys: true})
+}
+
+func configSet(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	key, value := args[0], args[1]
+	account, _ := cmd.Flags().GetString("account")
+	if account != "" {
+		if checkErr := checkAccount(account); checkErr != nil {
+			return checkErr
+		}
+	}
+	cfg, err := updateConfig(func(cfg *model.Config) error {
+		if account == "" {
+			return setConfigKey(cfg, key, value)
+		}
+		return setAccountConfig(cfg, account, key, value)
+	})
+	if err != nil {
+		return err
+	}
+	if account != "" {
+		return p.Result(cfg, output.View{RawKeys: true}, "Set %s = %s for account %s", key, value, account)
+	}
+	return p.Result(cfg, output.View{RawKeys: true}, "Set %s = %s", key, value)
 }
 
-func configSet(_ *cobra.Command, args []string) error {
-	cfg, err := loadConfig()
+func configUnset(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	key := args[0]
+	def, ok := configDefaults[key]
+	if !ok {
+		return fmt.Errorf("unknown config key: %s (valid: %s)", key, configKeys)
+	}
+	account, _ := cmd.Flags().GetString("account")
+	cfg, err := updateConfig(func(cfg *model.Config) error {
+		if account == "" {
+			return setConfigKey(cfg, key, def)
+		}
+		// Overrides left by an account removed before 'auth remove' dropped
+		// them can still be unset.
+		if _, stale := cfg.Accounts[account]; !stale {
+			if checkErr := checkAccount(account); checkErr != nil {
+				return checkErr
+			}
+		}
+		return unsetAccountConfig(cfg, account, key)
+	})
 	if err != nil {
 		return err
 	}
-	switch args[0] {
+	if account != "" {
+		return p.Result(cfg, output.View{RawKeys: true}, "Removed %s override for account %s", key, account)
+	}
+	return p.Result(cfg, output.View{RawKeys: true}, "Reset %s to its default", key)
+}
+
+// setConfigKey validates value and stores it under key.
+func setConfigKey(cfg *model.Config, key, value string) error {
+	switch key {
 	case "send_email":
-		cfg.SendEmail = args[1] == "true"
+		cfg.SendEmail = value == "true"
 	case "audience":
-		if !validAudience(args[1]) {
-			return fmt.Errorf("invalid audience: %s (valid: %v)", args[1], validAudiences)
+		if !validAudience(value) {
+			return fmt.Errorf("invalid audience: %s (valid: %v)", value, validAudiences)
 		}
-		cfg.Audience = args[1]
+		cfg.Audience = value
 	case "section":
-		cfg.Section = args[1]
+		cfg.Section = value
 	case "output_format":
-		if !validOutputFormat(args[1]) {
-			return fmt.Errorf("invalid output_format: %s (valid: %v)", args[1], validOutputFormats)
+		format := value
+		if format == "text" { // older name for table
+			format = output.Table
+		}
+		if !validOutputFormat(format) {
+			return fmt.Errorf("invalid output_format: %s (valid: %v)", value, output.Formats)
+		}
+		cfg.OutputFormat = format
+	case "timeout", "request_timeout":
+		if _, durErr := parseTimeout(value); durErr != nil {
+			return fmt.Errorf("invalid %s: %s (use a duration like 30s or 2m, or 0 to disable)", key, value)
+		}
+		if key == "timeout" {
+			cfg.Timeout = value
+		} else {
+			cfg.RequestTimeout = value
 		}
-		cfg.OutputFormat = args[1]
+	case "max_attempts":
+		n, convErr := strconv.Atoi(value)
+		if convErr != nil || n < 0 {
+			return fmt.Errorf("invalid max_attempts: %s (use 1 to disable retries, 0 for the default)", value)
+		}
+		cfg.MaxAttempts = n
+	case "timezone":
+		if _, tzErr := time.LoadLocation(value); tzErr != nil {
+			return fmt.Errorf("invalid timezone: %s (use an IANA name like America/New_York)", value)
+		}
+		cfg.Timezone = value
 	default:
-		return fmt.Errorf("unknown config key: %s (valid: send_email, audience, section, output_format)", args[0])
-	}
-	if saveErr := saveConfig(cfg); saveErr != nil {
-		return saveErr
+		return fmt.Errorf("unknown config key: %s (valid: %s)", key, configKeys)
 	}
-	fmt.Fprintf(os.Stdout, "Set %s = %s\n", args[0], args[1])
 	return nil
 }
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyAccountConfig(t *testing.T) {
+	cfg := &model.Config{Audience: "everyone", OutputFormat: "table"}
+	if err := setConfigKey(cfg, "audience", "only_paid"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setAccountConfig(cfg, "work", "audience", "only_free"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setAccountConfig(cfg, "work", "output_format", "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setAccountConfig(cfg, "work", "audience", "nobody"); err == nil {
+		t.Error("expected error for an invalid override")
+	}
+
+	effective := func(name string) model.Config {
+		t.Helper()
+		c := *cfg
+		if err := applyAccountConfig(&c, name); err != nil {
+			t.Fatal(err)
+		}
+		if c.Accounts != nil {
+			t.Errorf("%s: overrides left in the effective config: %v", name, c.Accounts)
+		}
+		return c
+	}
+	if got := effective("work"); got.Audience != "only_free" || got.OutputFormat != "json" {
+		t.Errorf("work: audience %q, output %q; want its overrides", got.Audience, got.OutputFormat)
+	}
+	for _, name := range []string{"personal", ""} {
+		if got := effective(name); got.Audience != "only_paid" || got.OutputFormat != "table" {
+			t.Errorf("%q: audience %q, output %q; want the global values", name, got.Audience, got.OutputFormat)
+		}
+	}
+
+	if err := unsetAccountConfig(cfg, "work", "audience"); err != nil {
+		t.Fatal(err)
+	}
+	if got := effective("work"); got.Audience != "only_paid" || got.OutputFormat != "json" {
+		t.Errorf("after unset: audience %q, output %q; want global audience and json", got.Audience, got.OutputFormat)
+	}
+	if err := unsetAccountConfig(cfg, "work", "audience"); err == nil {
+		t.Error("expected error for an override that is not set")
+	}
+	if err := unsetAccountConfig(cfg, "work", "output_format"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Accounts["work"]; ok {
+		t.Errorf("accounts = %v, want work dropped with its last override", cfg.Accounts)
+	}
+}
+
+// useConfigDir points the config and account store at a fresh directory
+// holding the stored account "work".
+func useConfigDir(t *testing.T) {
+	t.Helper()
+	configDir = t.TempDir()
+	sharedStore = nil
+	t.Cleanup(func() { configDir, sharedStore = "", nil })
+	for _, env := range []string{accountEnv, auth.EnvCredentialsFile, auth.EnvPublicationURL, auth.EnvSID,
+		auth.EnvSubstackSID, auth.EnvSubstackLLI} {
+		t.Setenv(env, "")
+	}
+	store := &model.AccountStore{}
+	auth.AddAccount(store, model.Account{Name: "work", PublicationURL: "https://work.substack.com"})
+	if err := (&auth.Store{Path: filepath.Join(configDir, "accounts.json")}).Save(store); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// accountCmd returns a command with the --account flag, set to name.
+func accountCmd(t *testing.T, name string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().String("account", "", "")
+	if err := cmd.Flags().Set("account", name); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestAccountConfigFollowsStore(t *testing.T) {
+	useConfigDir(t)
+
+	if err := checkAccount("work"); err != nil {
+		t.Errorf("stored account: %v", err)
+	}
+	if err := checkAccount(auth.EnvAccountName); err != nil {
+		t.Errorf("environment account: %v", err)
+	}
+	var usageErr usageError
+	if err := checkAccount("typo"); !errors.As(err, &usageErr) {
+		t.Errorf("unknown account: err = %v, want a usage error", err)
+	}
+
+	// Without overrides, removing an account must not create config.json.
+	if err := dropAccountConfig("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "config.json")); !os.IsNotExist(err) {
+		t.Errorf("config.json written without overrides: %v", err)
+	}
+	_, err := updateConfig(func(cfg *model.Config) error {
+		return setAccountConfig(cfg, "work", "audience", "only_paid")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropErr := dropAccountConfig("work"); dropErr != nil {
+		t.Fatal(dropErr)
+	}
+	cfg, err := readConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Accounts) != 0 {
+		t.Errorf("accounts = %v, want the removed account's overrides gone", cfg.Accounts)
+	}
+}
+
+func TestLoadConfigAccount(t *testing.T) {
+	useConfigDir(t)
+	_, err := updateConfig(func(cfg *model.Config) error {
+		return setAccountConfig(cfg, "work", "audience", "only_paid")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(accountCmd(t, ""))
+	if err != nil || cfg.Audience != "only_paid" {
+		t.Errorf("active account: audience %v, %v; want its override", cfg, err)
+	}
+
+	// Broken credentials from the environment must not break the config
+	// every command loads; they only mean no overrides.
+	t.Setenv(auth.EnvSID, "sid")
+	cfg, err = loadConfig(accountCmd(t, ""))
+	if err != nil || cfg.Audience != "everyone" {
+		t.Errorf("invalid env: audience %v, %v; want the global value", cfg, err)
+	}
+
+	var usageErr usageError
+	if err := configShow(accountCmd(t, "typo"), nil); !errors.As(err, &usageErr) {
+		t.Errorf("config show --account typo: err = %v, want a usage error", err)
+	}
+}
+
+func TestInvalidStoredTimeoutIsIgnored(t *testing.T) {
+	useConfigDir(t)
+	data := []byte(`{"version":2,"timeout":"bogus"}`)
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := accountCmd(t, "")
+	cmd.Flags().Duration("timeout", 0, "")
+	d, err := resolveTimeout(cmd, "timeout", func(cfg *model.Config) string { return cfg.Timeout }, 0)
+	if err != nil || d != 0 {
+		t.Errorf("timeout = %v, %v; want the default without an error", d, err)
+	}
+}
+
+func TestLoadConfigOncePerInvocation(t *testing.T) {
+	useConfigDir(t)
+	cmd := accountCmd(t, "")
+	cmd.SetContext(context.WithValue(context.Background(), configKey{}, &model.Config{Audience: "only_paid"}))
+	data := []byte(`{"version":2,"audience":"everyone"}`)
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil || cfg.Audience != "only_paid" {
+		t.Fatalf("audience %v, %v; want the config loaded for the invocation", cfg, err)
+	}
+	cfg.Audience = "changed"
+	if again, _ := loadConfig(cmd); again.Audience != "only_paid" {
+		t.Errorf("a caller's change leaked into the next load: %q", again.Audience)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// document is a markdown file converted to Substack's format but not yet
+// uploaded.
+type document struct {
+	path   string
+	source []byte
+	fm     *markdown.Frontmatter
+	title  string
+	body   model.DraftBody
+}
+
+func loadDocument(path string) (*document, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	fm, title, body, err := markdown.ConvertWithFrontmatter(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	warnFrontmatter(path, fm)
+	return &document{path: path, source: source, fm: fm, title: title, body: body}, nil
+}
+
+// draftRequest builds the draft metadata from config defaults overridden by
+// frontmatter. The body is attached by upload once images are hosted.
+func (d *document) draftRequest(cfg *model.Config, loc *time.Location) (model.DraftRequest, error) {
+	draft := model.DraftRequest{
+		Title:    d.title,
+		Audience: cfg.Audience,
+		Section:  cfg.Section,
+	}
+	if d.fm != nil {
+		if d.fm.Subtitle != "" {
+			draft.Subtitle = d.fm.Subtitle
+		}
+		if d.fm.Audience != "" {
+			draft.Audience = d.fm.Audience
+		}
+		if d.fm.Section != "" {
+			draft.Section = d.fm.Section
+		}
+	}
+	draft.SectionChosen = draft.Section != ""
+	if err := applyFrontmatter(&draft, d.fm, loc); err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+// upload hosts the document's images and cover image, then sets the draft body.
+func (d *document) upload(ctx context.Context, client *api.Client, draft *model.DraftRequest, rehost bool) error {
+	baseDir := filepath.Dir(d.path)
+	if err := uploadImages(ctx, client, d.body, baseDir, rehost); err != nil {
+		return err
+	}
+	if err := uploadCoverImage(ctx, client, draft, baseDir, rehost); err != nil {
+		return err
+	}
+	bodyJSON, err := json.Marshal(d.body)
+	if err != nil {
+		return fmt.Errorf("marshaling body: %w", err)
+	}
+	draft.DraftBody = string(bodyJSON)
+	return nil
+}
+
+// tags returns the frontmatter tags, if any.
+func (d *document) tags() []string {
+	if d.fm == nil {
+		return nil
+	}
+	return d.fm.Tags
+}
@@ -1,12 +1,19 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
+	"github.com/aaronsrivastava/substack-cli/internal/listing"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
 	"github.com/spf13/cobra"
 )
@@ -32,9 +39,29 @@ func init() {
 		RunE:  draftList,
 	}
 	listCmd.Flags().String("format", "", "Output format: text or json")
+	_ = listCmd.Flags().MarkDeprecated("format", "use --output")
+	addListFlags(listCmd)
+
+	updateCmd := &cobra.Command{
+		Use:   "update <id> <file.md>",
+		Short: "Replace a draft's content and metadata from markdown",
+		Args:  cobra.ExactArgs(2),
+		RunE:  draftUpdate,
+	}
+	updateCmd.Flags().Bool("check-remote", false,
+		"Refuse if the draft changed remotely since the file was pulled")
+	updateCmd.Flags().Bool("rehost-images", false, "Upload remote images to Substack instead of linking them")
+
+	pullCmd := &cobra.Command{
+		Use:   "pull [id...]",
+		Short: "Export drafts as markdown files",
+		RunE:  draftPull,
+	}
+	addPullFlags(pullCmd)
 
 	draftCmd.AddCommand(
 		listCmd,
+		pullCmd,
 		&cobra.Command{
 			Use:   "get <id>",
 			Short: "Get draft details",
@@ -48,93 +75,94 @@ func init() {
 			RunE:  draftDelete,
 		},
 		publishCmd,
+		updateCmd,
 	)
 
 	rootCmd.AddCommand(draftCmd)
 }
 
 func draftList(cmd *cobra.Command, _ []string) error {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	format := cfg.OutputFormat
-	if cmd.Flags().Changed("format") {
-		format, _ = cmd.Flags().GetString("format")
-	}
-
-	client, err := api.NewClient()
+	loc, err := configLocation(cfg)
 	if err != nil {
 		return err
 	}
-	drafts, err := client.ListDrafts()
+	query, err := listQueryFrom(cmd, loc)
 	if err != nil {
 		return err
 	}
 
-	if format == "json" {
-		data, marshalErr := json.MarshalIndent(drafts, "", "  ")
-		if marshalErr != nil {
-			return marshalErr
-		}
-		fmt.Fprintln(os.Stdout, string(data))
-		return nil
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-
-	if len(drafts) == 0 {
-		fmt.Fprintln(os.Stdout, "No drafts.")
-		return nil
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
 	}
-	for _, d := range drafts {
-		fmt.Fprintf(os.Stdout, "%-8d %s\n", d.ID, d.Title)
+	drafts, err := fetchList(ctx, query, client.Drafts, listing.FromDraft)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	return p.List(drafts, draftListView)
 }
 
-func draftGet(_ *cobra.Command, args []string) error {
+func draftGet(cmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid draft id: %s", args[0])
+		return usageError{fmt.Errorf("invalid draft id: %s", args[0])}
 	}
-	client, err := api.NewClient()
+	p, err := newPrinter(cmd)
 	if err != nil {
 		return err
 	}
-	d, err := client.GetDraft(id)
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "ID:       %d\nTitle:    %s\nSubtitle: %s\nSlug:     %s\nAudience: %s\n",
-		d.ID, d.Title, d.Subtitle, d.Slug, d.Audience)
-	return nil
+	d, err := client.GetDraft(ctx, id)
+	if err != nil {
+		return err
+	}
+	return p.Object(d, draftView)
 }
 
-func draftDelete(_ *cobra.Command, args []string) error {
+func draftDelete(cmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid draft id: %s", args[0])
+		return usageError{fmt.Errorf("invalid draft id: %s", args[0])}
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-	client, err := api.NewClient()
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	if deleteErr := client.DeleteDraft(id); deleteErr != nil {
+	if deleteErr := client.DeleteDraft(ctx, id); deleteErr != nil {
 		return deleteErr
 	}
-	fmt.Fprintf(os.Stdout, "Draft %d deleted.\n", id)
-	return nil
+	return p.Result(actionResult{ID: id, Status: "deleted"}, resultView, "Draft %d deleted.", id)
 }
 
 func draftPublish(cmd *cobra.Command, args []string) error {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid draft id: %s", args[0])
+		return usageError{fmt.Errorf("invalid draft id: %s", args[0])}
 	}
 
 	sendEmail := cfg.SendEmail
@@ -147,17 +175,169 @@ func draftPublish(cmd *cobra.Command, args []string) error {
 		audience, _ = cmd.Flags().GetString("audience")
 	}
 
-	client, err := api.NewClient()
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	post, err := client.PublishDraft(id, model.PublishOptions{
+	post, err := client.PublishDraft(ctx, id, model.PublishOptions{
 		SendEmail: sendEmail,
 		Audience:  audience,
 	})
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "Published: id=%d slug=%q\n", post.ID, post.Slug)
+	result := actionResult{ID: post.ID, Title: post.Title, Slug: post.Slug, Status: "published"}
+	return p.Result(result, resultView, "Published: id=%d slug=%q", post.ID, post.Slug)
+}
+
+func draftPull(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	ids, err := pullIDs(cmd, args, func() ([]int, error) {
+		drafts, listErr := client.ListDrafts(ctx, api.ListOptions{})
+		ids := make([]int, 0, len(drafts))
+		for _, d := range drafts {
+			ids = append(ids, d.ID)
+		}
+		return ids, listErr
+	})
+	if err != nil {
+		return err
+	}
+	opts := pullOptionsFrom(cmd)
+	return pullEach(p, ids, func(id int) (string, error) {
+		d, getErr := client.GetDraft(ctx, id)
+		if getErr != nil {
+			return "", getErr
+		}
+		if d.DraftBody == "" {
+			return "", errors.New("draft has no document body")
+		}
+		body, decodeErr := markdown.DecodeBody(d.DraftBody)
+		if decodeErr != nil {
+			return "", decodeErr
+		}
+		fm := markdown.FrontmatterFromDraft(*d)
+		return writeMarkdown(ctx, client, opts, pullName(d.Slug, "draft", id), fm, body)
+	})
+}
+
+func draftUpdate(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return usageError{fmt.Errorf("invalid draft id: %s", args[0])}
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	loc, err := configLocation(cfg)
+	if err != nil {
+		return err
+	}
+	doc, err := loadDocument(args[1])
+	if err != nil {
+		return err
+	}
+	// Only the file's own metadata is sent; config defaults apply when a
+	// draft is created, and must not overwrite settings changed since.
+	draft, err := doc.draftRequest(&model.Config{}, loc)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	if check, _ := cmd.Flags().GetBool("check-remote"); check {
+		if checkErr := checkRemoteUnchanged(ctx, client, id, doc); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	rehost, _ := cmd.Flags().GetBool("rehost-images")
+	if uploadErr := doc.upload(ctx, client, &draft, rehost); uploadErr != nil {
+		return uploadErr
+	}
+	resp, err := client.UpdateDraft(ctx, id, draft)
+	if err != nil {
+		return err
+	}
+	if tags := doc.tags(); len(tags) > 0 {
+		if tagErr := client.SetPostTags(ctx, id, tags); tagErr != nil {
+			return fmt.Errorf("tagging draft: %w", tagErr)
+		}
+	}
+	if refreshErr := refreshRemoteUpdatedAt(doc, resp.DraftUpdated); refreshErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record remote_updated_at %s in %s: %v; pull the draft again "+
+			"before the next --check-remote\n", resp.DraftUpdated.UTC().Format(time.RFC3339Nano), doc.path, refreshErr)
+	}
+	result := actionResult{ID: resp.ID, Title: resp.Title, Status: "updated"}
+	return p.Result(result, resultView, "Updated: id=%d title=%q", resp.ID, resp.Title)
+}
+
+// refreshRemoteUpdatedAt rewrites the file's remote_updated_at to the time
+// of the update just made, so the next --check-remote does not take our own
+// change for a remote edit. Files without the field are left alone.
+func refreshRemoteUpdatedAt(doc *document, updated time.Time) error {
+	if doc.fm == nil || doc.fm.RemoteUpdatedAt == "" || updated.IsZero() {
+		return nil
+	}
+	// Replace the value in place so the rest of the file, in whichever
+	// frontmatter format, stays exactly as written.
+	old := []byte(doc.fm.RemoteUpdatedAt)
+	i := bytes.Index(doc.source, old)
+	if i < 0 {
+		return errors.New("the pulled value is not written verbatim in the file")
+	}
+	value := updated.UTC().Format(time.RFC3339Nano)
+	data := slices.Concat(doc.source[:i], []byte(value), doc.source[i+len(old):])
+	info, err := os.Stat(doc.path)
+	if err != nil {
+		return err
+	}
+	if writeErr := filestore.WriteAtomic(doc.path, data, info.Mode().Perm()); writeErr != nil {
+		return writeErr
+	}
+	doc.source, doc.fm.RemoteUpdatedAt = data, value
+	return nil
+}
+
+// checkRemoteUnchanged compares the draft's remote modification time with
+// the remote_updated_at recorded in the file when it was pulled.
+func checkRemoteUnchanged(ctx context.Context, client *api.Client, id int, doc *document) error {
+	if doc.fm == nil || doc.fm.RemoteUpdatedAt == "" {
+		return fmt.Errorf("%s has no remote_updated_at; pull the draft first or drop --check-remote", doc.path)
+	}
+	pulled, err := time.Parse(time.RFC3339Nano, doc.fm.RemoteUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("invalid remote_updated_at in %s: %w", doc.path, err)
+	}
+	remote, err := client.GetDraft(ctx, id)
+	if err != nil {
+		return err
+	}
+	if remote.DraftUpdated.After(pulled) {
+		return fmt.Errorf("draft %d changed remotely at %s, after %s was pulled (%s); pull it again to merge",
+			id, remote.DraftUpdated.UTC().Format(time.RFC3339), doc.path, pulled.Format(time.RFC3339))
+	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRefreshRemoteUpdatedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.md")
+	source := "---\ntitle: Hello\nremote_updated_at: \"2024-03-01T09:00:00Z\"\n---\n\nBody\n"
+	if err := os.WriteFile(path, []byte(source), 0640); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := time.Date(2024, 3, 2, 10, 30, 0, 500, time.FixedZone("EST", -5*3600))
+	if refreshErr := refreshRemoteUpdatedAt(doc, updated); refreshErr != nil {
+		t.Fatal(refreshErr)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: Hello\nremote_updated_at: \"2024-03-02T15:30:00.0000005Z\"\n---\n\nBody\n"
+	if string(data) != want {
+		t.Errorf("file =\n%s\nwant\n%s", data, want)
+	}
+	reloaded, err := loadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.fm.RemoteUpdatedAt != "2024-03-02T15:30:00.0000005Z" {
+		t.Errorf("remote_updated_at = %q", reloaded.fm.RemoteUpdatedAt)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
+)
+
+// Exit codes. Scripts can rely on these to tell failure classes apart.
+const (
+	exitError       = 1   // anything not covered below
+	exitUsage       = 2   // bad flags or arguments
+	exitAuth        = 3   // session missing, expired or not permitted
+	exitNotFound    = 4   // the post, draft or other resource does not exist
+	exitInvalid     = 5   // Substack rejected the request content
+	exitUnavailable = 6   // rate limited or Substack server error
+	exitTimeout     = 7   // --timeout or --request-timeout exceeded
+	exitInterrupted = 130 // Ctrl-C or SIGTERM
+)
+
+// usageError marks errors caused by how the command was invoked.
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// reportError prints err with a hint for well-known failures and returns the
+// exit code for it.
+func reportError(w io.Writer, err error) int {
+	fmt.Fprintf(w, "Error: %v\n", err)
+	code, hint := classifyError(err)
+	if hint != "" {
+		fmt.Fprintln(w, hint)
+	}
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" && code == exitUnavailable {
+		fmt.Fprintf(w, "Request ID: %s\n", apiErr.RequestID)
+	}
+	return code
+}
+
+func classifyError(err error) (int, string) {
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr), errors.Is(err, output.ErrUnknownColumn):
+		return exitUsage, "Run with --help for usage."
+	case api.IsSessionExpired(err):
+		return exitAuth, sessionHint(err)
+	case api.IsUnauthorized(err):
+		return exitAuth, "Your Substack session is missing or expired. Run 'substack auth login' to sign in again."
+	case api.IsForbidden(err):
+		return exitAuth, "The active account is not allowed to do that. Check 'substack auth status'."
+	case api.IsNotFound(err):
+		return exitNotFound, "Not found. Check the ID with 'substack post list' or 'substack draft list'."
+	case api.IsValidation(err):
+		return exitInvalid, "Substack rejected the request. Check the values sent and try again."
+	case api.IsRateLimited(err):
+		return exitUnavailable, "Substack is rate limiting requests. Wait a minute and try again."
+	case api.IsServerError(err):
+		return exitUnavailable, "Substack returned a server error. Try again later."
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout, "The command timed out; raise --timeout or --request-timeout."
+	}
+	return exitError, ""
+}
+
+// sessionHint tells how to renew an expired session: log in again for a
+// stored account, or supply fresh cookies for credentials from the
+// environment, which no login can update.
+func sessionHint(err error) string {
+	var sessionErr *api.SessionError
+	if !errors.As(err, &sessionErr) || sessionErr.Account == "" {
+		return "Your Substack session has expired. Run 'substack auth login' to sign in again."
+	}
+	name := sessionErr.Account
+	if acct, ok, _ := auth.FromEnv(); name == auth.EnvAccountName || (ok && acct.Name == name) {
+		return fmt.Sprintf("The credentials from the environment have expired. Set %s or %s (or %s) to fresh cookies.",
+			auth.EnvSID, auth.EnvSubstackSID, auth.EnvCredentialsFile)
+	}
+	return fmt.Sprintf("Re-authenticate account %[1]s: run 'substack auth login --name %[1]s' "+
+		"(or --email), or 'substack auth import-browser --name %[1]s'.", name)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/auth"
+)
+
+func TestSessionExpiredHint(t *testing.T) {
+	for _, env := range []string{auth.EnvCredentialsFile, auth.EnvPublicationURL, auth.EnvSID,
+		auth.EnvSubstackSID, auth.EnvSubstackLLI} {
+		t.Setenv(env, "")
+	}
+	for _, tt := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"stored account", &api.SessionError{Account: "work", Err: &api.APIError{StatusCode: 401}},
+			"auth login --name work"},
+		{"environment", fmt.Errorf("listing: %w", &api.SessionError{Account: auth.EnvAccountName}),
+			auth.EnvSubstackSID},
+		// Wrapping the sentinel alone must not crash the error reporter.
+		{"bare sentinel", fmt.Errorf("listing: %w", api.ErrSessionExpired), "substack auth login'"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := classifyError(tt.err)
+			if code != exitAuth || !strings.Contains(hint, tt.want) {
+				t.Errorf("classifyError = %d, %q; want %d and a hint with %q", code, hint, exitAuth, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// timeLayouts are the accepted formats for frontmatter dates, most specific first.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// parseTime parses a frontmatter or CLI timestamp. Values without an explicit
+// offset are interpreted in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339 or YYYY-MM-DD [HH:MM])", s)
+}
+
+// applyFrontmatter copies the metadata-only frontmatter fields onto draft.
+// Title, subtitle, audience and section are resolved by the caller since they
+// also have config defaults and CLI flags.
+func applyFrontmatter(draft *model.DraftRequest, fm *markdown.Frontmatter, loc *time.Location) error {
+	if fm == nil {
+		return nil
+	}
+	draft.Slug = fm.Slug
+	draft.SearchTitle = fm.SEOTitle
+	draft.SearchDescription = fm.MetaDescription
+	draft.CoverImage = fm.SocialImage
+	draft.CanonicalURL = fm.CanonicalURL
+	draft.PodcastURL = fm.PodcastURL
+	if fm.Date != "" {
+		t, err := parseTime(fm.Date, loc)
+		if err != nil {
+			return fmt.Errorf("frontmatter date: %w", err)
+		}
+		draft.PostDate = t.UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+// warnFrontmatter prints non-fatal frontmatter problems for path to stderr.
+func warnFrontmatter(path string, fm *markdown.Frontmatter) {
+	if fm == nil {
+		return
+	}
+	for _, w := range fm.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", path, w)
+	}
+}
+
+// uploadCoverImage replaces a local cover image path with its uploaded URL.
+func uploadCoverImage(
+	ctx context.Context, client *api.Client, draft *model.DraftRequest, baseDir string, rehost bool,
+) error {
+	if draft.CoverImage == "" || (isRemoteURL(draft.CoverImage) && !rehost) {
+		return nil
+	}
+	upload, err := uploadImage(ctx, client, draft.CoverImage, baseDir)
+	if err != nil {
+		return err
+	}
+	draft.CoverImage = upload.URL
+	return nil
+}
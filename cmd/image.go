@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// uploadImages uploads every local image referenced in body and rewrites the
+// image nodes to point at the hosted copies. Relative paths resolve against
+// baseDir. Remote images are left alone unless rehost is set.
+func uploadImages(ctx context.Context, client *api.Client, body model.DraftBody, baseDir string, rehost bool) error {
+	uploaded := map[string]*model.ImageUpload{}
+	return markdown.WalkImages(body, func(attrs map[string]any) error {
+		src, _ := attrs["src"].(string)
+		if src == "" || (isRemoteURL(src) && !rehost) {
+			return nil
+		}
+		upload, ok := uploaded[src]
+		if !ok {
+			var err error
+			if upload, err = uploadImage(ctx, client, src, baseDir); err != nil {
+				return err
+			}
+			uploaded[src] = upload
+		}
+		attrs["src"] = upload.URL
+		attrs["bytes"] = upload.Bytes
+		attrs["type"] = upload.ContentType
+		if upload.Width > 0 && upload.Height > 0 {
+			attrs["width"] = upload.Width
+			attrs["height"] = upload.Height
+		}
+		return nil
+	})
+}
+
+// uploadImage reads a local path or downloads a remote URL and uploads it.
+func uploadImage(ctx context.Context, client *api.Client, src, baseDir string) (*model.ImageUpload, error) {
+	var data []byte
+	var err error
+	if isRemoteURL(src) {
+		data, err = client.DownloadImage(ctx, src)
+	} else {
+		data, err = os.ReadFile(localImagePath(src, baseDir))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading image %s: %w", src, err)
+	}
+	upload, err := client.UploadImage(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("uploading image %s: %w", src, err)
+	}
+	fmt.Fprintf(os.Stderr, "Uploaded image: %s\n", src)
+	return upload, nil
+}
+
+func isRemoteURL(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+func localImagePath(src, baseDir string) string {
+	src = strings.TrimPrefix(src, "file://")
+	if filepath.IsAbs(src) {
+		return src
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(src))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/listing"
+	"github.com/spf13/cobra"
+)
+
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("limit", 0, "Maximum number of items to show (0 for no limit)")
+	cmd.Flags().Int("offset", 0, "Number of items to skip")
+	cmd.Flags().Bool("all", false, "Show every item, the default without --limit")
+	cmd.Flags().String("since", "", "Only items dated on or after this date/time")
+	cmd.Flags().String("until", "", "Only items dated before this time, or on or before this date")
+	cmd.Flags().String("audience", "", "Only items for this audience: everyone, only_paid, only_free")
+	cmd.Flags().String("section", "", "Only items in this section ID")
+	cmd.Flags().String("search", "", "Only items whose title, subtitle or slug contains this text")
+	cmd.Flags().String("sort", "", "Sort by "+strings.Join(listing.SortKeys, ", ")+" (default API order)")
+	cmd.Flags().Bool("reverse", false, "Reverse the order")
+}
+
+// listQuery is what the list flags ask for. Filters and sorting need every
+// item, so when any are set the whole listing is fetched and offset/limit
+// apply to the filtered, sorted result instead of to API pages.
+type listQuery struct {
+	opts    api.ListOptions
+	filter  listing.Filter
+	sort    string
+	reverse bool
+}
+
+func (q listQuery) clientSide() bool {
+	return !q.filter.IsZero() || q.sort != "" || q.reverse
+}
+
+func listQueryFrom(cmd *cobra.Command, loc *time.Location) (listQuery, error) {
+	var q listQuery
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		if cmd.Flags().Changed("limit") {
+			return q, usageError{errors.New("--all and --limit cannot be combined")}
+		}
+		limit = 0
+	} else if limit < 0 {
+		return q, usageError{errors.New("--limit must not be negative")}
+	}
+	if offset < 0 {
+		return q, usageError{errors.New("--offset must not be negative")}
+	}
+	q.opts = api.ListOptions{Offset: offset, Limit: limit}
+
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	var err error
+	if since != "" {
+		if q.filter.Since, err = parseTime(since, loc); err != nil {
+			return q, usageError{fmt.Errorf("--since: %w", err)}
+		}
+	}
+	if until != "" {
+		if q.filter.Until, err = parseTime(until, loc); err != nil {
+			return q, usageError{fmt.Errorf("--until: %w", err)}
+		}
+		if _, dateErr := time.Parse(time.DateOnly, until); dateErr == nil {
+			// A bare date includes the whole day.
+			q.filter.Until = q.filter.Until.AddDate(0, 0, 1)
+		}
+	}
+	q.filter.Audience, _ = cmd.Flags().GetString("audience")
+	if q.filter.Audience != "" && !validAudience(q.filter.Audience) {
+		return q, usageError{fmt.Errorf("invalid audience: %s (valid: %v)", q.filter.Audience, validAudiences)}
+	}
+	q.filter.Section, _ = cmd.Flags().GetString("section")
+	q.filter.Search, _ = cmd.Flags().GetString("search")
+	q.opts.Search = q.filter.Search
+
+	q.sort, _ = cmd.Flags().GetString("sort")
+	if q.sort != "" && !slices.Contains(listing.SortKeys, q.sort) {
+		return q, usageError{fmt.Errorf("invalid --sort: %s (valid: %s)", q.sort, strings.Join(listing.SortKeys, ", "))}
+	}
+	q.reverse, _ = cmd.Flags().GetBool("reverse")
+	return q, nil
+}
+
+// fetchList runs q against a paginated listing such as client.Posts.
+func fetchList[T any](
+	ctx context.Context, q listQuery,
+	list func(context.Context, api.ListOptions) iter.Seq2[T, error], describe func(T) listing.Item,
+) ([]T, error) {
+	opts := q.opts
+	if q.clientSide() {
+		opts = api.ListOptions{Search: q.opts.Search}
+	}
+	items := []T{}
+	for item, err := range list(ctx, opts) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if !q.clientSide() {
+		return items, nil
+	}
+	return applyList(items, q, describe)
+}
+
+// applyList runs q against a listing that is already fully fetched.
+func applyList[T any](items []T, q listQuery, describe func(T) listing.Item) ([]T, error) {
+	items = slices.DeleteFunc(items, func(item T) bool { return !q.filter.Match(describe(item)) })
+	if err := listing.Sort(items, describe, q.sort, q.reverse); err != nil {
+		return nil, err
+	}
+	return listing.Window(items, q.opts.Offset, q.opts.Limit), nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/listing"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func listCmd(t *testing.T, flags map[string]string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	addListFlags(cmd)
+	for name, value := range flags {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cmd
+}
+
+func TestListQueryDefaultsToEverything(t *testing.T) {
+	q, err := listQueryFrom(listCmd(t, nil), time.UTC)
+	if err != nil || q.opts.Limit != 0 {
+		t.Errorf("limit = %d, %v; want no limit by default", q.opts.Limit, err)
+	}
+	var usageErr usageError
+	if _, err := listQueryFrom(listCmd(t, map[string]string{"limit": "-1"}), time.UTC); !errors.As(err, &usageErr) {
+		t.Errorf("negative --limit: err = %v, want a usage error", err)
+	}
+	both := listCmd(t, map[string]string{"limit": "5", "all": "true"})
+	if _, err := listQueryFrom(both, time.UTC); !errors.As(err, &usageErr) {
+		t.Errorf("--all with --limit: err = %v, want a usage error", err)
+	}
+}
+
+func TestApplyListToScheduled(t *testing.T) {
+	drafts := []model.DraftResponse{
+		{ID: 1, Title: "Launch", PostDate: "2030-01-01T09:00:00Z"},
+		{ID: 2, Title: "Recap", PostDate: "2030-02-01T09:00:00Z"},
+		{ID: 3, Title: "Launch follow-up", PostDate: "2030-03-01T09:00:00Z"},
+	}
+	q, err := listQueryFrom(listCmd(t, map[string]string{"search": "launch", "sort": "date", "limit": "1"}), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := applyList(drafts, q, listing.FromScheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, 0, len(got))
+	for _, d := range got {
+		ids = append(ids, d.ID)
+	}
+	if !slices.Equal(ids, []int{3}) {
+		t.Errorf("ids = %v, want the latest matching draft", ids)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aaronsrivastava/substack-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Table layouts. Columns are JSON field names of the listed values.
+var (
+	postListView  = output.View{Columns: []string{"id", "post_date", "title"}, Empty: "No published posts."}
+	draftListView = output.View{Columns: []string{"id", "draft_title"}, Empty: "No drafts."}
+	scheduledView = output.View{Columns: []string{"id", "post_date", "draft_title"}, Empty: "No scheduled posts."}
+	postView      = output.View{Columns: []string{"id", "title", "subtitle", "slug", "audience", "post_date"}}
+	draftView     = output.View{Columns: []string{"id", "draft_title", "draft_subtitle", "slug", "audience"}}
+	accountsView  = output.View{
+		Columns: []string{"name", "publication_url", "active"},
+		Empty:   "No accounts configured. Run 'substack auth login'.",
+	}
+	sessionsView = output.View{
+		Columns: []string{"account", "status", "name", "role", "publication"},
+		Empty:   "No accounts configured. Run 'substack auth login'.",
+	}
+	resultView = output.View{}
+)
+
+// actionResult is what commands that change something print for scripts.
+// People get a one-line confirmation instead.
+type actionResult struct {
+	ID     int    `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+	Path   string `json:"path,omitempty"`
+	At     string `json:"at,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newPrinter builds the printer for --output, --columns and --template. The
+// format falls back to a command's deprecated --format flag, then to the
+// output_format config key.
+func newPrinter(cmd *cobra.Command) (*output.Printer, error) {
+	format, _ := cmd.Flags().GetString("output")
+	if !cmd.Flags().Changed("output") {
+		if legacy := cmd.Flags().Lookup("format"); legacy != nil && legacy.Changed {
+			format = legacy.Value.String()
+		} else {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return nil, err
+			}
+			format = cfg.OutputFormat
+		}
+	}
+	if format == "text" {
+		format = output.Table
+	}
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+	tmpl, _ := cmd.Flags().GetString("template")
+	p, err := output.New(os.Stdout, format, columns, tmpl)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	return p, nil
+}
+
+// progress reports a step on stdout for people, or on stderr when stdout is
+// reserved for machine-readable output.
+func progress(p *output.Printer, format string, args ...any) {
+	w := os.Stdout
+	if !p.Human() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format+"\n", args...)
+}
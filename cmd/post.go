@@ -1,15 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strconv"
+	"time"
 
 	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/listing"
 	"github.com/aaronsrivastava/substack-cli/internal/markdown"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +33,7 @@ func init() {
 	createCmd.Flags().Bool("send-email", false, "Send email to subscribers")
 	createCmd.Flags().String("audience", "", "Audience: everyone, only_paid, only_free")
 	createCmd.Flags().String("section", "", "Section/category for the post")
+	createCmd.Flags().Bool("rehost-images", false, "Upload remote images to Substack instead of linking them")
 
 	updateCmd := &cobra.Command{
 		Use:   "update <id>",
@@ -49,9 +52,20 @@ func init() {
 		RunE:  postList,
 	}
 	listCmd.Flags().String("format", "", "Output format: text or json")
+	_ = listCmd.Flags().MarkDeprecated("format", "use --output")
+	listCmd.Flags().Bool("scheduled", false, "List drafts scheduled for future publication")
+	addListFlags(listCmd)
+
+	pullCmd := &cobra.Command{
+		Use:   "pull [id...]",
+		Short: "Export published posts as markdown files",
+		RunE:  postPull,
+	}
+	addPullFlags(pullCmd)
 
 	postCmd.AddCommand(
 		createCmd,
+		pullCmd,
 		listCmd,
 		&cobra.Command{
 			Use:   "get <id>",
@@ -66,174 +80,212 @@ func init() {
 			RunE:  postUnpublish,
 		},
 		updateCmd,
+		&cobra.Command{
+			Use:   "schedule <id> <time>",
+			Short: "Schedule a draft for publication (RFC 3339 or YYYY-MM-DD HH:MM)",
+			Args:  cobra.ExactArgs(2),
+			RunE:  postSchedule,
+		},
+		&cobra.Command{
+			Use:   "unschedule <id>",
+			Short: "Cancel a scheduled publication",
+			Args:  cobra.ExactArgs(1),
+			RunE:  postUnschedule,
+		},
 	)
 
 	rootCmd.AddCommand(postCmd)
 }
 
 func postCreate(cmd *cobra.Command, args []string) error {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	source, err := os.ReadFile(args[0])
+	doc, err := loadDocument(args[0])
 	if err != nil {
-		return fmt.Errorf("reading file: %w", err)
+		return err
 	}
+	fm := doc.fm
 
-	fm, title, body := markdown.ConvertWithFrontmatter(source)
-
-	bodyJSON, err := json.Marshal(body)
+	loc, err := configLocation(cfg)
 	if err != nil {
-		return fmt.Errorf("marshaling body: %w", err)
+		return err
 	}
 
-	// Start from config defaults, then let frontmatter override, then CLI args override.
-	subtitle := ""
-	audience := cfg.Audience
-	section := cfg.Section
-
-	if fm != nil {
-		if fm.Subtitle != "" {
-			subtitle = fm.Subtitle
+	// Decide how the post goes live before anything is created remotely.
+	publish, _ := cmd.Flags().GetBool("publish")
+	var scheduledAt time.Time
+	if fm != nil && fm.Draft && publish {
+		return errors.New("refusing to publish: frontmatter sets draft: true")
+	}
+	if fm != nil && !fm.Draft && fm.ScheduledAt != "" {
+		if publish {
+			return errors.New("--publish conflicts with scheduled_at in frontmatter")
 		}
-		if fm.Audience != "" {
-			audience = fm.Audience
+		if scheduledAt, err = parseTime(fm.ScheduledAt, loc); err != nil {
+			return fmt.Errorf("frontmatter scheduled_at: %w", err)
 		}
-		if fm.Section != "" {
-			section = fm.Section
+		if !scheduledAt.After(time.Now()) {
+			return fmt.Errorf("scheduled_at %s is in the past", scheduledAt.Format(time.RFC3339))
 		}
 	}
 
-	// CLI args override frontmatter
+	// Start from config defaults and frontmatter, then CLI args override.
+	draft, err := doc.draftRequest(cfg, loc)
+	if err != nil {
+		return err
+	}
 	if cmd.Flags().Changed("title") {
-		title, _ = cmd.Flags().GetString("title")
+		draft.Title, _ = cmd.Flags().GetString("title")
 	}
 	if cmd.Flags().Changed("subtitle") {
-		subtitle, _ = cmd.Flags().GetString("subtitle")
+		draft.Subtitle, _ = cmd.Flags().GetString("subtitle")
 	}
 	if cmd.Flags().Changed("audience") {
-		audience, _ = cmd.Flags().GetString("audience")
+		draft.Audience, _ = cmd.Flags().GetString("audience")
 	}
 	if cmd.Flags().Changed("section") {
-		section, _ = cmd.Flags().GetString("section")
+		draft.Section, _ = cmd.Flags().GetString("section")
+		draft.SectionChosen = draft.Section != ""
 	}
 
-	draft := model.DraftRequest{
-		Title:         title,
-		Subtitle:      subtitle,
-		DraftBody:     string(bodyJSON),
-		Audience:      audience,
-		Section:       section,
-		SectionChosen: section != "",
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-
-	client, err := api.NewClient()
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.CreateDraft(draft)
+	rehost, _ := cmd.Flags().GetBool("rehost-images")
+	if uploadErr := doc.upload(ctx, client, &draft, rehost); uploadErr != nil {
+		return uploadErr
+	}
+
+	resp, err := client.CreateDraft(ctx, draft)
 	if err != nil {
 		return fmt.Errorf("creating draft: %w", err)
 	}
-	fmt.Fprintf(os.Stdout, "Draft created: id=%d title=%q\n", resp.ID, resp.Title)
+	result := actionResult{ID: resp.ID, Title: resp.Title, Status: "draft"}
+	progress(p, "Draft created: id=%d title=%q", resp.ID, resp.Title)
 
-	publish, _ := cmd.Flags().GetBool("publish")
-	if cmd.Flags().Changed("publish") && publish {
+	if tags := doc.tags(); len(tags) > 0 {
+		if tagErr := client.SetPostTags(ctx, resp.ID, tags); tagErr != nil {
+			return fmt.Errorf("tagging draft: %w", tagErr)
+		}
+	}
+
+	if !scheduledAt.IsZero() {
+		if scheduleErr := client.SchedulePost(ctx, resp.ID, scheduledAt); scheduleErr != nil {
+			return fmt.Errorf("scheduling: %w", scheduleErr)
+		}
+		result.Status, result.At = "scheduled", scheduledAt.Format(time.RFC3339)
+		progress(p, "Scheduled: id=%d at %s", resp.ID, result.At)
+	}
+
+	if publish {
 		sendEmail, _ := cmd.Flags().GetBool("send-email")
 		opts := model.PublishOptions{
 			SendEmail: sendEmail,
-			Audience:  audience,
+			Audience:  draft.Audience,
 		}
-		post, publishErr := client.PublishDraft(resp.ID, opts)
+		post, publishErr := client.PublishDraft(ctx, resp.ID, opts)
 		if publishErr != nil {
 			return fmt.Errorf("publishing: %w", publishErr)
 		}
-		fmt.Fprintf(os.Stdout, "Published: id=%d slug=%q\n", post.ID, post.Slug)
+		result.Status, result.Slug = "published", post.Slug
+		progress(p, "Published: id=%d slug=%q", post.ID, post.Slug)
 	}
 
-	return nil
+	if p.Human() {
+		return nil
+	}
+	return p.Object(result, resultView)
 }
 
 func postList(cmd *cobra.Command, _ []string) error {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	format := cfg.OutputFormat
-	if cmd.Flags().Changed("format") {
-		format, _ = cmd.Flags().GetString("format")
-	}
-
-	client, err := api.NewClient()
+	loc, err := configLocation(cfg)
 	if err != nil {
 		return err
 	}
-	posts, err := client.ListPosts()
+	query, err := listQueryFrom(cmd, loc)
 	if err != nil {
 		return err
 	}
 
-	if format == "json" {
-		data, marshalErr := json.MarshalIndent(posts, "", "  ")
-		if marshalErr != nil {
-			return marshalErr
-		}
-		fmt.Fprintln(os.Stdout, string(data))
-		return nil
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-
-	if len(posts) == 0 {
-		fmt.Fprintln(os.Stdout, "No published posts.")
-		return nil
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
 	}
-	for _, p := range posts {
-		fmt.Fprintf(os.Stdout, "%-8d %s  %s\n", p.ID, p.PostDate, p.Title)
+	if scheduled, _ := cmd.Flags().GetBool("scheduled"); scheduled {
+		return listScheduled(ctx, client, p, query)
 	}
-	return nil
+	posts, err := fetchList(ctx, query, client.Posts, listing.FromPost)
+	if err != nil {
+		return err
+	}
+	return p.List(posts, postListView)
 }
 
-func postGet(_ *cobra.Command, args []string) error {
+func postGet(cmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid post id: %s", args[0])
+		return usageError{fmt.Errorf("invalid post id: %s", args[0])}
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-	client, err := api.NewClient()
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	post, err := client.GetPost(id)
+	post, err := client.GetPost(ctx, id)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "ID:       %d\nTitle:    %s\nSubtitle: %s\nSlug:     %s\nAudience: %s\nDate:     %s\n",
-		post.ID, post.Title, post.Subtitle, post.Slug, post.Audience, post.PostDate)
-	return nil
+	return p.Object(post, postView)
 }
 
-func postUnpublish(_ *cobra.Command, args []string) error {
+func postUnpublish(cmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid post id: %s", args[0])
+		return usageError{fmt.Errorf("invalid post id: %s", args[0])}
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
 	}
-	client, err := api.NewClient()
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	if unpublishErr := client.UnpublishPost(id); unpublishErr != nil {
+	if unpublishErr := client.UnpublishPost(ctx, id); unpublishErr != nil {
 		return unpublishErr
 	}
-	fmt.Fprintf(os.Stdout, "Post %d unpublished.\n", id)
-	return nil
+	return p.Result(actionResult{ID: id, Status: "unpublished"}, resultView, "Post %d unpublished.", id)
 }
 
 func postUpdate(cmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid post id: %s", args[0])
+		return usageError{fmt.Errorf("invalid post id: %s", args[0])}
 	}
 
 	updates := map[string]any{}
@@ -251,14 +303,135 @@ func postUpdate(cmd *cobra.Command, args []string) error {
 		return errors.New("no updates specified")
 	}
 
-	client, err := api.NewClient()
+	p, err := newPrinter(cmd)
 	if err != nil {
 		return err
 	}
-	post, err := client.UpdatePost(id, updates)
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "Updated: id=%d title=%q\n", post.ID, post.Title)
-	return nil
+	post, err := client.UpdatePost(ctx, id, updates)
+	if err != nil {
+		return err
+	}
+	result := actionResult{ID: post.ID, Title: post.Title, Status: "updated"}
+	return p.Result(result, resultView, "Updated: id=%d title=%q", post.ID, post.Title)
+}
+
+func listScheduled(ctx context.Context, client *api.Client, p *output.Printer, q listQuery) error {
+	drafts, err := client.ListScheduled(ctx)
+	if err != nil {
+		return err
+	}
+	if drafts, err = applyList(drafts, q, listing.FromScheduled); err != nil {
+		return err
+	}
+	return p.List(drafts, scheduledView)
+}
+
+func postSchedule(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return usageError{fmt.Errorf("invalid post id: %s", args[0])}
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	loc, err := configLocation(cfg)
+	if err != nil {
+		return err
+	}
+	at, err := parseTime(args[1], loc)
+	if err != nil {
+		return err
+	}
+	if !at.After(time.Now()) {
+		return fmt.Errorf("scheduled time %s is in the past", at.Format(time.RFC3339))
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	if scheduleErr := client.SchedulePost(ctx, id, at); scheduleErr != nil {
+		return scheduleErr
+	}
+	result := actionResult{ID: id, At: at.Format(time.RFC3339), Status: "scheduled"}
+	return p.Result(result, resultView, "Scheduled: id=%d at %s", id, result.At)
+}
+
+func postUnschedule(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return usageError{fmt.Errorf("invalid post id: %s", args[0])}
+	}
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	if unscheduleErr := client.UnschedulePost(ctx, id); unscheduleErr != nil {
+		return unscheduleErr
+	}
+	return p.Result(actionResult{ID: id, Status: "unscheduled"}, resultView, "Post %d unscheduled.", id)
+}
+
+func postPull(cmd *cobra.Command, args []string) error {
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	ids, err := pullIDs(cmd, args, func() ([]int, error) {
+		posts, listErr := client.ListPosts(ctx, api.ListOptions{})
+		ids := make([]int, 0, len(posts))
+		for _, p := range posts {
+			ids = append(ids, p.ID)
+		}
+		return ids, listErr
+	})
+	if err != nil {
+		return err
+	}
+	opts := pullOptionsFrom(cmd)
+	return pullEach(p, ids, func(id int) (string, error) {
+		post, getErr := client.GetPost(ctx, id)
+		if getErr != nil {
+			return "", getErr
+		}
+		raw := post.DraftBody
+		if raw == "" {
+			// The post endpoint may only return HTML; the draft endpoint
+			// shares IDs with posts and carries the document JSON.
+			d, draftErr := client.GetDraft(ctx, id)
+			if draftErr != nil {
+				return "", draftErr
+			}
+			raw = d.DraftBody
+		}
+		if raw == "" {
+			return "", errors.New("post has no document body")
+		}
+		body, decodeErr := markdown.DecodeBody(raw)
+		if decodeErr != nil {
+			return "", decodeErr
+		}
+		fm := markdown.FrontmatterFromPost(*post)
+		return writeMarkdown(ctx, client, opts, pullName(post.Slug, "post", id), fm, body)
+	})
 }
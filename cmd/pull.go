@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+type pullOptions struct {
+	dir    string
+	images bool
+	force  bool
+}
+
+func addPullFlags(cmd *cobra.Command) {
+	cmd.Flags().String("dir", ".", "Directory to write markdown files into")
+	cmd.Flags().Bool("all", false, "Pull every item instead of the given IDs")
+	cmd.Flags().Bool("no-images", false, "Keep remote image URLs instead of downloading images")
+	cmd.Flags().Bool("force", false, "Overwrite existing files")
+}
+
+func pullOptionsFrom(cmd *cobra.Command) pullOptions {
+	dir, _ := cmd.Flags().GetString("dir")
+	noImages, _ := cmd.Flags().GetBool("no-images")
+	force, _ := cmd.Flags().GetBool("force")
+	return pullOptions{dir: dir, images: !noImages, force: force}
+}
+
+// pullIDs parses the ID arguments, or lists every ID when --all is set.
+func pullIDs(cmd *cobra.Command, args []string, listAll func() ([]int, error)) ([]int, error) {
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return nil, usageError{errors.New("specify one or more IDs, or --all")}
+	}
+	if all {
+		return listAll()
+	}
+	ids := make([]int, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid id: %s", a)}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// pullEach runs pull for every ID, reporting failures without stopping so a
+// single bad post does not abort a large export. Scripts get one result per
+// ID at the end.
+func pullEach(p *output.Printer, ids []int, pull func(id int) (string, error)) error {
+	failed := 0
+	results := make([]actionResult, 0, len(ids))
+	for _, id := range ids {
+		path, err := pull(id)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed: id=%d: %v\n", id, err)
+			results = append(results, actionResult{ID: id, Status: "failed", Error: err.Error()})
+			continue
+		}
+		progress(p, "Pulled: id=%d -> %s", id, path)
+		results = append(results, actionResult{ID: id, Path: path, Status: "pulled"})
+	}
+	if !p.Human() {
+		if err := p.List(results, resultView); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pulls failed", failed, len(ids))
+	}
+	return nil
+}
+
+// writeMarkdown renders a document to <dir>/<name>.md. Unless disabled,
+// remote images are downloaded into <dir>/<name>/ and referenced relatively,
+// so the file can be fed straight back into post create.
+func writeMarkdown(
+	ctx context.Context, client *api.Client, opts pullOptions, name string, fm *markdown.Frontmatter, body model.DraftBody,
+) (string, error) {
+	target := filepath.Join(opts.dir, name+".md")
+	if _, err := os.Stat(target); err == nil && !opts.force {
+		return "", fmt.Errorf("%s already exists (use --force to overwrite)", target)
+	}
+
+	if opts.images {
+		images := &imageDownloader{client: client, dir: filepath.Join(opts.dir, name), rel: name}
+		if err := markdown.WalkImages(body, func(attrs map[string]any) error {
+			src, _ := attrs["src"].(string)
+			local, err := images.fetch(ctx, src)
+			if err != nil {
+				return err
+			}
+			attrs["src"] = local
+			return nil
+		}); err != nil {
+			return "", err
+		}
+		cover, err := images.fetch(ctx, fm.SocialImage)
+		if err != nil {
+			return "", err
+		}
+		fm.SocialImage = cover
+	}
+
+	doc, err := markdown.RenderDocument(fm, body)
+	if err != nil {
+		return "", err
+	}
+	if mkdirErr := os.MkdirAll(opts.dir, 0750); mkdirErr != nil {
+		return "", mkdirErr
+	}
+	if writeErr := os.WriteFile(target, doc, 0600); writeErr != nil {
+		return "", writeErr
+	}
+	return target, nil
+}
+
+// pullName picks the file name for a pulled item: its slug, or a fallback
+// for drafts that have not been given one yet.
+func pullName(slug, kind string, id int) string {
+	if slug = sanitizeFileName(slug); slug != "" {
+		return slug
+	}
+	return fmt.Sprintf("%s-%d", kind, id)
+}
+
+type imageDownloader struct {
+	client *api.Client
+	dir    string
+	rel    string
+	saved  map[string]string
+	used   map[string]bool
+}
+
+// fetch downloads a remote image once and returns its path relative to the
+// markdown file. Non-remote sources are returned unchanged.
+func (d *imageDownloader) fetch(ctx context.Context, src string) (string, error) {
+	if !isRemoteURL(src) {
+		return src, nil
+	}
+	if local, ok := d.saved[src]; ok {
+		return local, nil
+	}
+	data, err := d.client.DownloadImage(ctx, src)
+	if err != nil {
+		return "", err
+	}
+	if d.saved == nil {
+		d.saved, d.used = map[string]string{}, map[string]bool{}
+	}
+	name := d.uniqueName(imageFileName(src, data))
+	if mkdirErr := os.MkdirAll(d.dir, 0750); mkdirErr != nil {
+		return "", mkdirErr
+	}
+	if writeErr := os.WriteFile(filepath.Join(d.dir, name), data, 0600); writeErr != nil {
+		return "", writeErr
+	}
+	local := path.Join(d.rel, name)
+	d.saved[src] = local
+	return local, nil
+}
+
+func (d *imageDownloader) uniqueName(name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; d.used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	d.used[candidate] = true
+	return candidate
+}
+
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// imageFileName derives a safe local file name from an image URL. Substack
+// CDN fetch URLs embed the original URL as their last path segment.
+func imageFileName(src string, data []byte) string {
+	name := "image"
+	if u, err := url.Parse(src); err == nil {
+		if base := sanitizeFileName(path.Base(u.Path)); base != "" {
+			name = base
+		}
+	}
+	if path.Ext(name) == "" {
+		if ext, ok := imageExtensions[http.DetectContentType(data)]; ok {
+			name += ext
+		}
+	}
+	return name
+}
+
+func sanitizeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+	return strings.Trim(s, "-.")
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
+)
+
+// pngData is enough of a PNG for content sniffing.
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func imageServer(t *testing.T, downloads *atomic.Int32) (*api.Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write(pngData)
+	}))
+	t.Cleanup(srv.Close)
+	return api.NewClientWith(&model.Account{Name: "test", PublicationURL: srv.URL, SID: "sid"}), srv
+}
+
+func TestWriteMarkdownImages(t *testing.T) {
+	var downloads atomic.Int32
+	client, srv := imageServer(t, &downloads)
+	dir := t.TempDir()
+	image := func(src string) model.Node {
+		return model.Node{Type: "image2", Attrs: map[string]any{"src": src, "alt": "pic"}}
+	}
+	body := model.DraftBody{Type: "doc", Content: []model.Node{
+		image(srv.URL + "/images/photo.png"),
+		image(srv.URL + "/images/photo.png"),
+		image(srv.URL + "/images/noext"),
+		image("local/kept.png"),
+	}}
+	fm := &markdown.Frontmatter{Title: "Hello", SocialImage: srv.URL + "/images/..%2F..%2Fescape"}
+
+	target, err := writeMarkdown(t.Context(), client, pullOptions{dir: dir, images: true}, "hello", fm, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != filepath.Join(dir, "hello.md") {
+		t.Errorf("target = %s", target)
+	}
+	var srcs []string
+	for _, n := range body.Content {
+		srcs = append(srcs, n.Attrs["src"].(string))
+	}
+	want := []string{"hello/photo.png", "hello/photo.png", "hello/noext.png", "local/kept.png"}
+	if strings.Join(srcs, " ") != strings.Join(want, " ") {
+		t.Errorf("srcs = %v, want %v", srcs, want)
+	}
+	if fm.SocialImage != "hello/escape.png" {
+		t.Errorf("cover = %q, want it saved inside the image directory", fm.SocialImage)
+	}
+	if n := downloads.Load(); n != 3 {
+		t.Errorf("downloads = %d, want 3 (each image once)", n)
+	}
+	for _, name := range []string{"photo.png", "noext.png", "escape.png"} {
+		if _, statErr := os.Stat(filepath.Join(dir, "hello", name)); statErr != nil {
+			t.Errorf("image %s: %v", name, statErr)
+		}
+	}
+	if _, statErr := os.Stat(filepath.Join(filepath.Dir(dir), "escape.png")); !os.IsNotExist(statErr) {
+		t.Errorf("image written outside the pull directory: %v", statErr)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "hello/photo.png") {
+		t.Errorf("markdown does not reference the local image:\n%s", data)
+	}
+}
+
+func TestWriteMarkdownForce(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "post.md")
+	if err := os.WriteFile(target, []byte("mine"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	body := model.DraftBody{Type: "doc"}
+	opts := pullOptions{dir: dir}
+	_, err := writeMarkdown(t.Context(), nil, opts, "post", &markdown.Frontmatter{Title: "New"}, body)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("err = %v, want a hint at --force", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "mine" {
+		t.Errorf("existing file changed without --force: %q", data)
+	}
+
+	opts.force = true
+	if _, err := writeMarkdown(t.Context(), nil, opts, "post", &markdown.Frontmatter{Title: "New"}, body); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); !strings.Contains(string(data), "New") {
+		t.Errorf("file not overwritten with --force: %q", data)
+	}
+}
+
+func TestImageFileName(t *testing.T) {
+	for _, tt := range []struct{ src, want string }{
+		{"https://cdn.example.com/img/photo.jpg", "photo.jpg"},
+		{"https://cdn.example.com/img/no-extension", "no-extension.png"},
+		{"https://cdn.example.com/..%2F..%2Fetc%2Fpasswd", "passwd.png"},
+		{"https://cdn.example.com/", "image.png"},
+		{"https://cdn.example.com/we%20ird%3Bname.gif", "we-ird-name.gif"},
+	} {
+		if got := imageFileName(tt.src, pngData); got != tt.want {
+			t.Errorf("imageFileName(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"my-post_1.md", "my-post_1.md"},
+		{"../../x", "x"},
+		{"/etc/passwd", "etc-passwd"},
+		{"..", ""},
+		{"héllo wörld", "h-llo-w-rld"},
+	} {
+		if got := sanitizeFileName(tt.in); got != tt.want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := pullName("../..", "draft", 7); got != "draft-7" {
+		t.Errorf("pullName = %q, want the fallback", got)
+	}
+}
+
+func TestPullEachReportsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := output.New(&buf, output.JSON, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pullEach(p, []int{1, 2, 3}, func(id int) (string, error) {
+		if id == 2 {
+			return "", errors.New("boom")
+		}
+		return filepath.Join("out", "post.md"), nil
+	})
+	if err == nil || err.Error() != "1 of 3 pulls failed" {
+		t.Errorf("err = %v, want 1 of 3 pulls failed", err)
+	}
+	var results []actionResult
+	if decodeErr := json.Unmarshal(buf.Bytes(), &results); decodeErr != nil {
+		t.Fatalf("%v: %s", decodeErr, buf.String())
+	}
+	if len(results) != 3 || results[1].Status != "failed" || results[1].Error != "boom" || results[2].Status != "pulled" {
+		t.Errorf("results = %+v, want every ID reported with the failure in place", results)
+	}
+}
+
+func TestInvalidIDIsUsageError(t *testing.T) {
+	cmd := accountCmd(t, "")
+	cmd.Flags().Bool("all", false, "")
+	for name, err := range map[string]error{
+		"draft get": draftGet(cmd, []string{"abc"}),
+		"post get":  postGet(cmd, []string{"12x"}),
+		"pull ids": func() error {
+			_, idsErr := pullIDs(cmd, []string{"1", "two"}, nil)
+			return idsErr
+		}(),
+		"pull nothing": func() error {
+			_, idsErr := pullIDs(cmd, nil, nil)
+			return idsErr
+		}(),
+	} {
+		if code, _ := classifyError(err); code != exitUsage {
+			t.Errorf("%s: exit code %d for %v, want %d", name, code, err, exitUsage)
+		}
+	}
+}
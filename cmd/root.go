@@ -1,20 +1,126 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/aaronsrivastava/substack-cli/internal/auth"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "substack",
-	Short: "CLI for managing Substack publications",
+	Use:               "substack",
+	Short:             "CLI for managing Substack publications",
+	PersistentPreRunE: beforeRun,
+	SilenceErrors:     true,
+}
+
+// cancelTimeout releases the command-wide deadline set by applyTimeout.
+var cancelTimeout context.CancelFunc = func() {}
+
+func init() {
+	rootCmd.PersistentFlags().String("account", "",
+		"Account to use for this command instead of the active one (or set "+accountEnv+")")
+	rootCmd.PersistentFlags().String("config-dir", "",
+		"Directory holding accounts.json and config.json (or set "+auth.EnvConfigDir+
+			"; default $XDG_CONFIG_HOME/substack-cli or ~/.config/substack-cli)")
+	rootCmd.PersistentFlags().StringP("output", "o", "",
+		"Output format: "+strings.Join(output.Formats, ", ")+" (default from config, or table)")
+	rootCmd.PersistentFlags().StringSlice("columns", nil, "Table and CSV columns, as JSON field names (e.g. id,slug)")
+	rootCmd.PersistentFlags().String("template", "", "Render each result with a Go template, e.g. '{{.ID}} {{.Slug}}'")
+	rootCmd.PersistentFlags().Duration("timeout", 0,
+		"Abort the whole command after this long, e.g. 5m (default from config, or no limit)")
+	rootCmd.PersistentFlags().Duration("request-timeout", 0,
+		"Abort a single HTTP request after this long (default from config, or 30s)")
+	rootCmd.PersistentFlags().Int("max-attempts", 0,
+		"Tries per request on rate limits and transient errors, 1 to disable retries (default from config, or 4)")
+}
+
+// configDir is --config-dir, or "" when it is not given.
+var configDir string
+
+// beforeRun runs once flags and arguments are known to be valid. Usage is
+// only printed for errors before this point, not for API or I/O failures.
+func beforeRun(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+	configDir, _ = cmd.Flags().GetString("config-dir")
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cmd.SetContext(context.WithValue(cmd.Context(), configKey{}, cfg))
+	return applyTimeout(cmd)
+}
+
+// applyTimeout bounds the command's context by --timeout or the config.
+func applyTimeout(cmd *cobra.Command) error {
+	timeout, err := resolveTimeout(cmd, "timeout", func(cfg *model.Config) string {
+		return cfg.Timeout
+	}, 0)
+	if err != nil || timeout == 0 {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	cancelTimeout = cancel
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// resolveTimeout returns the flag value when set, otherwise the config value,
+// otherwise def. Zero means no limit. An invalid config value only warns, so
+// it cannot lock the user out of the commands that would correct it.
+func resolveTimeout(
+	cmd *cobra.Command, flag string, fromConfig func(*model.Config) string, def time.Duration,
+) (time.Duration, error) {
+	if cmd.Flags().Changed(flag) {
+		d, _ := cmd.Flags().GetDuration(flag)
+		if d < 0 {
+			return 0, fmt.Errorf("--%s must not be negative", flag)
+		}
+		return d, nil
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("loading config: %w", err)
+	}
+	value := fromConfig(cfg)
+	if value == "" {
+		return def, nil
+	}
+	d, err := parseTimeout(value)
+	if err != nil {
+		key := strings.ReplaceAll(flag, "-", "_")
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid %s %q in config: %v\n", key, value, err)
+		return def, nil
+	}
+	return d, nil
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Ctrl-C or SIGTERM cancels the command's context, aborting any request
+	// in flight instead of leaving the process hung on the network.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	cancelTimeout()
+	interrupted := ctx.Err() != nil
+	stop()
+	if err == nil {
+		return
+	}
+	if interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(exitInterrupted)
+	}
+	if !cmd.SilenceUsage {
+		// beforeRun never ran: the flags, arguments or command name were wrong.
+		err = usageError{err}
 	}
+	os.Exit(reportError(os.Stderr, err))
 }
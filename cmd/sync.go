@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/markdown"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/output"
+	"github.com/aaronsrivastava/substack-cli/internal/syncstate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	syncCmd := &cobra.Command{
+		Use:   "sync <dir>",
+		Short: "Create or update drafts from a directory of markdown files",
+		Long: "Sync every markdown file under <dir> to a draft. New files create drafts, changed files\n" +
+			"update their draft, unchanged files are skipped. File-to-draft mappings and content hashes\n" +
+			"are kept in a state file (" + syncstate.DefaultFile + " in <dir> by default). Sync never\n" +
+			"publishes or deletes anything.",
+		Args: cobra.ExactArgs(1),
+		RunE: runSync,
+	}
+	syncCmd.Flags().Bool("dry-run", false, "Show the plan without applying it")
+	syncCmd.Flags().String("state", "", "State file path (default <dir>/"+syncstate.DefaultFile+")")
+	syncCmd.Flags().Bool("rehost-images", false, "Upload remote images to Substack instead of linking them")
+
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	loc, err := configLocation(cfg)
+	if err != nil {
+		return err
+	}
+
+	statePath, _ := cmd.Flags().GetString("state")
+	if statePath == "" {
+		statePath = filepath.Join(dir, syncstate.DefaultFile)
+	}
+	state, err := syncstate.Load(statePath)
+	if err != nil {
+		return fmt.Errorf("loading sync state: %w", err)
+	}
+
+	p, err := newPrinter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	pub := client.Account.PublicationURL
+	if state.Publication != "" && state.Publication != pub {
+		return fmt.Errorf("%s tracks %s, not %s (use --state for a separate state file)",
+			statePath, state.Publication, pub)
+	}
+	state.Publication = pub
+
+	// Parse everything up front so a broken file fails the sync before any
+	// remote changes are made.
+	files, err := syncstate.FindMarkdown(dir)
+	if err != nil {
+		return err
+	}
+	docs := make(map[string]*document, len(files))
+	hashes := make(map[string]string, len(files))
+	for _, f := range files {
+		doc, loadErr := loadDocument(filepath.Join(dir, filepath.FromSlash(f)))
+		if loadErr != nil {
+			return loadErr
+		}
+		docs[f] = doc
+		hashes[f] = documentHash(doc)
+	}
+
+	changes := syncstate.Plan(state, hashes)
+	results := make([]actionResult, len(changes))
+	for i, c := range changes {
+		results[i] = actionResult{ID: c.Entry.ID, Path: c.Path, Status: string(c.Action)}
+	}
+	if p.Human() {
+		printSyncPlan(dir, pub, changes)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		if p.Human() {
+			return nil
+		}
+		return p.List(results, resultView)
+	}
+
+	rehost, _ := cmd.Flags().GetBool("rehost-images")
+	err = applySync(p, changes, results, func(c syncstate.Change) (int, error) {
+		return syncFile(ctx, client, state, statePath, docs[c.Path], c, cfg, loc, rehost)
+	})
+	if !p.Human() {
+		if listErr := p.List(results, resultView); listErr != nil && err == nil {
+			err = listErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return syncstate.Save(state, statePath)
+}
+
+// applySync runs apply for every create or update, recording the outcome in
+// results and stopping at the first failure.
+func applySync(
+	p *output.Printer, changes []syncstate.Change, results []actionResult, apply func(syncstate.Change) (int, error),
+) error {
+	for i, c := range changes {
+		if c.Action != syncstate.Create && c.Action != syncstate.Update {
+			continue
+		}
+		id, err := apply(c)
+		if err != nil {
+			if id != 0 {
+				results[i].ID = id
+			}
+			results[i].Status, results[i].Error = "failed", err.Error()
+			return fmt.Errorf("%s: %w", c.Path, err)
+		}
+		verb := "Created"
+		results[i].Status = "created"
+		if c.Action == syncstate.Update {
+			verb = "Updated"
+			results[i].Status = "updated"
+		}
+		results[i].ID = id
+		progress(p, "%s: %s (id=%d)", verb, c.Path, id)
+	}
+	return nil
+}
+
+// syncFile syncs one file and records its draft in state, saving the state
+// after every file so a failure part-way keeps earlier progress. A draft that
+// exists but was not fully synced, e.g. because tagging failed, is recorded
+// without a hash, so the next run updates it instead of creating a duplicate.
+func syncFile(
+	ctx context.Context, client *api.Client, state *syncstate.State, statePath string, doc *document,
+	c syncstate.Change, cfg *model.Config, loc *time.Location, rehost bool,
+) (int, error) {
+	id, syncErr := syncDocument(ctx, client, doc, c, cfg, loc, rehost)
+	if id == 0 {
+		return 0, syncErr
+	}
+	hash := c.Hash
+	if syncErr != nil {
+		hash = ""
+	}
+	state.Files[c.Path] = syncstate.Entry{ID: id, Hash: hash, SyncedAt: time.Now().UTC()}
+	if saveErr := syncstate.Save(state, statePath); saveErr != nil {
+		return id, errors.Join(syncErr, fmt.Errorf("saving sync state: %w", saveErr))
+	}
+	return id, syncErr
+}
+
+func syncDocument(
+	ctx context.Context, client *api.Client, doc *document, c syncstate.Change,
+	cfg *model.Config, loc *time.Location, rehost bool,
+) (int, error) {
+	draft, err := doc.draftRequest(cfg, loc)
+	if err != nil {
+		return 0, err
+	}
+	if uploadErr := doc.upload(ctx, client, &draft, rehost); uploadErr != nil {
+		return 0, uploadErr
+	}
+	id := c.Entry.ID
+	if c.Action == syncstate.Create {
+		resp, createErr := client.CreateDraft(ctx, draft)
+		if createErr != nil {
+			return 0, fmt.Errorf("creating draft: %w", createErr)
+		}
+		id = resp.ID
+	} else if _, updateErr := client.UpdateDraft(ctx, id, draft); updateErr != nil {
+		return 0, fmt.Errorf("updating draft %d: %w", id, updateErr)
+	}
+	if tags := doc.tags(); len(tags) > 0 {
+		if tagErr := client.SetPostTags(ctx, id, tags); tagErr != nil {
+			return id, fmt.Errorf("tagging draft %d: %w", id, tagErr)
+		}
+	}
+	return id, nil
+}
+
+func printSyncPlan(dir, pub string, changes []syncstate.Change) {
+	counts := map[syncstate.Action]int{}
+	fmt.Fprintf(os.Stdout, "Plan for %s -> %s:\n", dir, pub)
+	for _, c := range changes {
+		counts[c.Action]++
+		switch c.Action {
+		case syncstate.Create:
+			fmt.Fprintf(os.Stdout, "  create   %s\n", c.Path)
+		case syncstate.Update:
+			fmt.Fprintf(os.Stdout, "  update   %s (id=%d)\n", c.Path, c.Entry.ID)
+		case syncstate.Skip:
+			fmt.Fprintf(os.Stdout, "  skip     %s (id=%d)\n", c.Path, c.Entry.ID)
+		case syncstate.Missing:
+			fmt.Fprintf(os.Stdout, "  missing  %s (id=%d, file removed; draft left in place)\n", c.Path, c.Entry.ID)
+		}
+	}
+	fmt.Fprintf(os.Stdout, "%d to create, %d to update, %d unchanged.\n",
+		counts[syncstate.Create], counts[syncstate.Update], counts[syncstate.Skip])
+}
+
+// documentHash covers the markdown source and any local images it references,
+// so replacing an image file without touching the text still triggers an update.
+func documentHash(doc *document) string {
+	parts := [][]byte{doc.source}
+	baseDir := filepath.Dir(doc.path)
+	addImage := func(src string) {
+		if src == "" || isRemoteURL(src) {
+			return
+		}
+		if data, err := os.ReadFile(localImagePath(src, baseDir)); err == nil {
+			parts = append(parts, data)
+		}
+	}
+	_ = markdown.WalkImages(doc.body, func(attrs map[string]any) error {
+		src, _ := attrs["src"].(string)
+		addImage(src)
+		return nil
+	})
+	if doc.fm != nil {
+		addImage(doc.fm.SocialImage)
+	}
+	return syncstate.Hash(parts...)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/aaronsrivastava/substack-cli/internal/syncstate"
+)
+
+// TestSyncRecordsDraftWhenTaggingFails checks that a draft created before a
+// later step fails is still recorded, so the next sync updates it instead of
+// creating a duplicate.
+func TestSyncRecordsDraftWhenTaggingFails(t *testing.T) {
+	var creates, updates atomic.Int32
+	var tagsDown atomic.Bool
+	tagsDown.Store(true)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/publication/users", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 123, "role": "admin"}]`))
+	})
+	mux.HandleFunc("POST /api/v1/drafts/", func(w http.ResponseWriter, _ *http.Request) {
+		creates.Add(1)
+		_, _ = w.Write([]byte(`{"id": 77}`))
+	})
+	mux.HandleFunc("PUT /api/v1/drafts/77", func(w http.ResponseWriter, _ *http.Request) {
+		updates.Add(1)
+		_, _ = w.Write([]byte(`{"id": 77}`))
+	})
+	mux.HandleFunc("GET /api/v1/publication/post-tag", func(w http.ResponseWriter, _ *http.Request) {
+		if tagsDown.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": "t1", "name": "news"}]`))
+	})
+	mux.HandleFunc("POST /api/v1/post/77/tag/t1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client := api.NewClientWith(&model.Account{Name: "test", PublicationURL: srv.URL, UserID: "123", SID: "sid"})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "post.md")
+	if err := os.WriteFile(path, []byte("---\ntags: [news]\n---\n# Title\n\nBody\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, syncstate.DefaultFile)
+	cfg := &model.Config{Audience: "everyone"}
+	run := func() error {
+		state, loadErr := syncstate.Load(statePath)
+		if loadErr != nil {
+			t.Fatal(loadErr)
+		}
+		hashes := map[string]string{"post.md": documentHash(doc)}
+		for _, c := range syncstate.Plan(state, hashes) {
+			if c.Action == syncstate.Skip {
+				continue
+			}
+			if _, syncErr := syncFile(t.Context(), client, state, statePath, doc, c, cfg, time.UTC, false); syncErr != nil {
+				return syncErr
+			}
+		}
+		return nil
+	}
+
+	if err := run(); err == nil {
+		t.Fatal("expected the tagging failure to be reported")
+	}
+	state, err := syncstate.Load(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := state.Files["post.md"]; entry.ID != 77 || entry.Hash != "" {
+		t.Errorf("entry after failure = %+v, want id 77 without a hash", entry)
+	}
+
+	tagsDown.Store(false)
+	if err := run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(); err != nil {
+		t.Fatal(err)
+	}
+	if creates.Load() != 1 || updates.Load() != 1 {
+		t.Errorf("creates = %d, updates = %d; want 1 and 1", creates.Load(), updates.Load())
+	}
+}
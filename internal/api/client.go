@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aaronsrivastava/substack-cli/internal/auth"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
@@ -16,27 +18,48 @@ import (
 
 const (
 	httpBadRequestThreshold = 400
+
+	// DefaultRequestTimeout bounds a single HTTP request, including reading
+	// the response body. Callers can change it through Client.HTTP.Timeout.
+	DefaultRequestTimeout = 30 * time.Second
 )
 
 type Client struct {
 	HTTP    *http.Client
 	Account *model.Account
+	Retry   RetryPolicy
+	// OnCookiesRotated, when set, is called with the updated account after
+	// Substack replaces a session cookie, so the new values can be saved.
+	OnCookiesRotated func(model.Account)
+	jar              *sessionJar
 }
 
+// NewClient builds a client for the account NewClientFor would pick from the
+// default store.
 func NewClient() (*Client, error) {
-	store, err := auth.Load()
+	store, err := auth.DefaultStore("")
 	if err != nil {
 		return nil, err
 	}
-	acct, err := auth.GetActive(store)
+	return NewClientFor(store, "")
+}
+
+// NewClientFor builds a client for the named account in store or, when name
+// is empty, for credentials from the environment or else the active account
+// (see Store.Resolve). The store's active account is left unchanged.
+func NewClientFor(store *auth.Store, name string) (*Client, error) {
+	acct, err := store.Resolve(name)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{HTTP: &http.Client{}, Account: acct}, nil
+	return NewClientWith(acct), nil
 }
 
 func NewClientWith(acct *model.Account) *Client {
-	return &Client{HTTP: &http.Client{}, Account: acct}
+	c := &Client{Account: acct, Retry: DefaultRetryPolicy}
+	c.jar = &sessionJar{client: c}
+	c.HTTP = &http.Client{Timeout: DefaultRequestTimeout, Jar: c.jar}
+	return c
 }
 
 func (c *Client) baseURL() string {
@@ -45,34 +68,51 @@ func (c *Client) baseURL() string {
 	return url
 }
 
-func (c *Client) do(method, url string, body any) (*http.Response, error) {
-	var reader io.Reader
+func (c *Client) do(ctx context.Context, method, url string, body any) (*http.Response, error) {
+	return c.request(ctx, method, url, body, idempotentMethod(method))
+}
+
+// doIdempotent is do for POST endpoints that are safe to repeat, so they get
+// the same retries as GET and PUT.
+func (c *Client) doIdempotent(ctx context.Context, method, url string, body any) (*http.Response, error) {
+	return c.request(ctx, method, url, body, true)
+}
+
+// doOnce is do for PUT endpoints with side effects beyond the stored
+// resource, such as publishing, which can email every subscriber. They are
+// retried only when the server cannot have acted on them.
+func (c *Client) doOnce(ctx context.Context, method, url string, body any) (*http.Response, error) {
+	return c.request(ctx, method, url, body, false)
+}
+
+func (c *Client) request(ctx context.Context, method, url string, body any, idempotent bool) (*http.Response, error) {
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
 			return nil, err
 		}
-		reader = bytes.NewReader(data)
 	}
-	req, err := http.NewRequestWithContext(context.Background(), method, url, reader)
-	if err != nil {
-		return nil, err
-	}
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.AddCookie(&http.Cookie{Name: "substack.sid", Value: c.Account.SubstackSID, Domain: ".substack.com"})
-	req.AddCookie(&http.Cookie{Name: "substack.lli", Value: c.Account.SubstackLLI, Domain: ".substack.com"})
-	req.AddCookie(&http.Cookie{Name: "connect.sid", Value: c.Account.SID, Domain: ".substack.com"})
-
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.send(ctx, idempotent, func() (*http.Request, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	cleared := c.jar != nil && c.jar.sessionCleared()
 	if resp.StatusCode >= httpBadRequestThreshold {
-		defer func() { _ = resp.Body.Close() }()
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		return nil, c.sessionError(newAPIError(resp), cleared)
 	}
 	return resp, nil
 }
@@ -86,35 +126,22 @@ func decodeJSON[T any](resp *http.Response) (T, error) {
 	return result, nil
 }
 
-func (c *Client) userID() (int, error) {
-	url := fmt.Sprintf("%s/api/v1/publication/users", c.baseURL())
-	resp, err := c.do("GET", url, nil)
-	if err != nil {
-		return 0, fmt.Errorf("fetching publication users: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-	raw, _ := io.ReadAll(resp.Body)
-	var users []struct {
-		ID   int    `json:"id"`
-		Role string `json:"role"`
+// userID returns the account's user ID for bylines: the stored one, else the
+// session's own user.
+func (c *Client) userID(ctx context.Context) (int, error) {
+	if id, err := strconv.Atoi(c.Account.UserID); err == nil {
+		return id, nil
 	}
-	if unmarshalErr := json.Unmarshal(raw, &users); unmarshalErr != nil {
-		return 0, fmt.Errorf("decoding publication users: %w", unmarshalErr)
-	}
-	for _, u := range users {
-		if u.Role == "admin" || u.Role == "owner" {
-			return u.ID, nil
-		}
-	}
-	if len(users) > 0 {
-		return users[0].ID, nil
+	u, err := c.Whoami(ctx)
+	if err != nil {
+		return 0, err
 	}
-	return 0, errors.New("no users found for publication")
+	return u.ID, nil
 }
 
-func (c *Client) CreateDraft(draft model.DraftRequest) (*model.DraftResponse, error) {
+func (c *Client) CreateDraft(ctx context.Context, draft model.DraftRequest) (*model.DraftResponse, error) {
 	if len(draft.DraftBylines) == 0 {
-		uid, err := c.userID()
+		uid, err := c.userID(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("resolving user ID for bylines: %w", err)
 		}
@@ -124,7 +151,7 @@ func (c *Client) CreateDraft(draft model.DraftRequest) (*model.DraftResponse, er
 		draft.Type = "newsletter"
 	}
 	url := fmt.Sprintf("%s/api/v1/drafts/", c.baseURL())
-	resp, err := c.do(http.MethodPost, url, draft)
+	resp, err := c.do(ctx, http.MethodPost, url, draft)
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +159,9 @@ func (c *Client) CreateDraft(draft model.DraftRequest) (*model.DraftResponse, er
 	return ptr(decodeJSON[model.DraftResponse](resp))
 }
 
-func (c *Client) GetDraft(id int) (*model.DraftResponse, error) {
+func (c *Client) GetDraft(ctx context.Context, id int) (*model.DraftResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/drafts/%d", c.baseURL(), id)
-	resp, err := c.do(http.MethodGet, url, nil)
+	resp, err := c.do(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -142,19 +169,15 @@ func (c *Client) GetDraft(id int) (*model.DraftResponse, error) {
 	return ptr(decodeJSON[model.DraftResponse](resp))
 }
 
-func (c *Client) ListDrafts() ([]model.DraftResponse, error) {
-	url := fmt.Sprintf("%s/api/v1/drafts/", c.baseURL())
-	resp, err := c.do(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-	return decodeJSON[[]model.DraftResponse](resp)
+// ListDrafts returns the drafts selected by opts, paging through
+// the API as needed. Use Drafts to stream large listings instead.
+func (c *Client) ListDrafts(ctx context.Context, opts ListOptions) ([]model.DraftResponse, error) {
+	return collect(c.Drafts(ctx, opts))
 }
 
-func (c *Client) DeleteDraft(id int) error {
+func (c *Client) DeleteDraft(ctx context.Context, id int) error {
 	url := fmt.Sprintf("%s/api/v1/drafts/%d", c.baseURL(), id)
-	resp, err := c.do(http.MethodDelete, url, nil)
+	resp, err := c.do(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
@@ -162,9 +185,9 @@ func (c *Client) DeleteDraft(id int) error {
 	return nil
 }
 
-func (c *Client) PublishDraft(id int, opts model.PublishOptions) (*model.Post, error) {
+func (c *Client) PublishDraft(ctx context.Context, id int, opts model.PublishOptions) (*model.Post, error) {
 	url := fmt.Sprintf("%s/api/v1/drafts/%d/publish", c.baseURL(), id)
-	resp, err := c.do(http.MethodPut, url, opts)
+	resp, err := c.doOnce(ctx, http.MethodPut, url, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -172,19 +195,15 @@ func (c *Client) PublishDraft(id int, opts model.PublishOptions) (*model.Post, e
 	return ptr(decodeJSON[model.Post](resp))
 }
 
-func (c *Client) ListPosts() ([]model.Post, error) {
-	url := fmt.Sprintf("%s/api/v1/posts/", c.baseURL())
-	resp, err := c.do(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-	return decodeJSON[[]model.Post](resp)
+// ListPosts returns the published posts selected by opts, paging
+// through the API as needed. Use Posts to stream large listings instead.
+func (c *Client) ListPosts(ctx context.Context, opts ListOptions) ([]model.Post, error) {
+	return collect(c.Posts(ctx, opts))
 }
 
-func (c *Client) GetPost(id int) (*model.Post, error) {
+func (c *Client) GetPost(ctx context.Context, id int) (*model.Post, error) {
 	url := fmt.Sprintf("%s/api/v1/posts/%d", c.baseURL(), id)
-	resp, err := c.do(http.MethodGet, url, nil)
+	resp, err := c.do(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -192,9 +211,9 @@ func (c *Client) GetPost(id int) (*model.Post, error) {
 	return ptr(decodeJSON[model.Post](resp))
 }
 
-func (c *Client) UnpublishPost(id int) error {
+func (c *Client) UnpublishPost(ctx context.Context, id int) error {
 	url := fmt.Sprintf("%s/api/v1/posts/%d/unpublish", c.baseURL(), id)
-	resp, err := c.do(http.MethodPut, url, nil)
+	resp, err := c.doOnce(ctx, http.MethodPut, url, nil)
 	if err != nil {
 		return err
 	}
@@ -202,9 +221,9 @@ func (c *Client) UnpublishPost(id int) error {
 	return nil
 }
 
-func (c *Client) UpdatePost(id int, updates map[string]any) (*model.Post, error) {
+func (c *Client) UpdatePost(ctx context.Context, id int, updates map[string]any) (*model.Post, error) {
 	url := fmt.Sprintf("%s/api/v1/drafts/%d", c.baseURL(), id)
-	resp, err := c.do(http.MethodPut, url, updates)
+	resp, err := c.do(ctx, http.MethodPut, url, updates)
 	if err != nil {
 		return nil, err
 	}
@@ -212,6 +231,79 @@ func (c *Client) UpdatePost(id int, updates map[string]any) (*model.Post, error)
 	return ptr(decodeJSON[model.Post](resp))
 }
 
+// UpdateDraft replaces a draft's content and metadata through the same
+// endpoint as UpdatePost. Bylines, type and section choice are only sent when
+// set, so an update never clears them.
+func (c *Client) UpdateDraft(ctx context.Context, id int, draft model.DraftRequest) (*model.DraftResponse, error) {
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return nil, err
+	}
+	var updates map[string]any
+	if unmarshalErr := json.Unmarshal(data, &updates); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	if len(draft.DraftBylines) == 0 {
+		delete(updates, "draft_bylines")
+	}
+	if draft.Type == "" {
+		delete(updates, "type")
+	}
+	if draft.Section == "" {
+		delete(updates, "section_chosen")
+	}
+	url := fmt.Sprintf("%s/api/v1/drafts/%d", c.baseURL(), id)
+	resp, err := c.do(ctx, http.MethodPut, url, updates)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return ptr(decodeJSON[model.DraftResponse](resp))
+}
+
+// SchedulePost schedules a draft to be published at the given time.
+func (c *Client) SchedulePost(ctx context.Context, id int, at time.Time) error {
+	url := fmt.Sprintf("%s/api/v1/drafts/%d/schedule", c.baseURL(), id)
+	resp, err := c.doIdempotent(ctx, http.MethodPost, url, map[string]any{"post_date": at.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// UnschedulePost clears a draft's scheduled publish time.
+func (c *Client) UnschedulePost(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/api/v1/drafts/%d/schedule", c.baseURL(), id)
+	resp, err := c.doIdempotent(ctx, http.MethodPost, url, map[string]any{"post_date": nil})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// ListScheduled returns drafts whose publish date is in the future, soonest first.
+func (c *Client) ListScheduled(ctx context.Context) ([]model.DraftResponse, error) {
+	drafts, err := c.ListDrafts(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var scheduled []model.DraftResponse
+	for _, d := range drafts {
+		if at, parseErr := time.Parse(time.RFC3339, d.PostDate); parseErr == nil && at.After(now) {
+			scheduled = append(scheduled, d)
+		}
+	}
+	slices.SortFunc(scheduled, func(a, b model.DraftResponse) int {
+		ta, _ := time.Parse(time.RFC3339, a.PostDate)
+		tb, _ := time.Parse(time.RFC3339, b.PostDate)
+		return ta.Compare(tb)
+	})
+	return scheduled, nil
+}
+
 func ptr[T any](v T, err error) (*T, error) {
 	if err != nil {
 		return nil, err
@@ -1,10 +1,17 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aaronsrivastava/substack-cli/internal/api"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
@@ -31,7 +38,7 @@ func TestCookiesSet(t *testing.T) {
 	})
 	defer srv.Close()
 
-	_, _ = client.ListDrafts()
+	_, _ = client.ListDrafts(t.Context(), api.ListOptions{})
 
 	names := map[string]string{}
 	for _, c := range gotCookies {
@@ -69,7 +76,7 @@ func TestCreateDraft(t *testing.T) {
 	})
 	defer srv.Close()
 
-	resp, err := client.CreateDraft(model.DraftRequest{Title: "Test"})
+	resp, err := client.CreateDraft(t.Context(), model.DraftRequest{Title: "Test"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -90,7 +97,7 @@ func TestListPosts(t *testing.T) {
 	})
 	defer srv.Close()
 
-	posts, err := client.ListPosts()
+	posts, err := client.ListPosts(t.Context(), api.ListOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -106,10 +113,78 @@ func TestAPIError(t *testing.T) {
 	})
 	defer srv.Close()
 
-	_, err := client.ListPosts()
+	_, err := client.ListPosts(t.Context(), api.ListOptions{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T, want *api.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Method != "GET" || apiErr.Endpoint != "/api/v1/posts/" {
+		t.Errorf("apiErr = %+v", apiErr)
+	}
+	if apiErr.Message != "forbidden" {
+		t.Errorf("Message = %q", apiErr.Message)
+	}
+	if !api.IsForbidden(err) || api.IsNotFound(err) || api.IsUnauthorized(err) {
+		t.Error("status helpers disagree with 403")
+	}
+}
+
+func TestAPIErrorPayloads(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantMsg    string
+		wantFields map[string]string
+		is         func(error) bool
+	}{
+		{"error key", http.StatusUnauthorized, `{"error":"Not authorized"}`, "Not authorized", nil, api.IsUnauthorized},
+		{"message key", http.StatusNotFound, `{"message":"Post not found"}`, "Post not found", nil, api.IsNotFound},
+		{
+			"field errors", http.StatusBadRequest,
+			`{"errors":[{"param":"draft_title","msg":"Too long"},{"msg":"Invalid draft"}]}`,
+			"Invalid draft", map[string]string{"draft_title": "Too long"}, api.IsValidation,
+		},
+		{"html page", http.StatusBadGateway, "<html>Bad gateway</html>", "Bad Gateway", nil, api.IsServerError},
+		{"empty body", http.StatusTooManyRequests, "", "Too Many Requests", nil, api.IsRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-Request-Id", "req-1")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			defer srv.Close()
+			client.Retry.MaxAttempts = 1
+
+			_, err := client.GetDraft(t.Context(), 5)
+			var apiErr *api.APIError
+			if !errors.As(fmt.Errorf("wrapped: %w", err), &apiErr) {
+				t.Fatalf("err = %v, want *api.APIError", err)
+			}
+			if apiErr.Message != tt.wantMsg {
+				t.Errorf("Message = %q, want %q", apiErr.Message, tt.wantMsg)
+			}
+			if len(apiErr.Fields) != len(tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", apiErr.Fields, tt.wantFields)
+			}
+			for k, v := range tt.wantFields {
+				if apiErr.Fields[k] != v {
+					t.Errorf("Fields[%s] = %q, want %q", k, apiErr.Fields[k], v)
+				}
+			}
+			if apiErr.RequestID != "req-1" {
+				t.Errorf("RequestID = %q", apiErr.RequestID)
+			}
+			if !tt.is(err) {
+				t.Errorf("status helper false for %d", tt.status)
+			}
+		})
+	}
 }
 
 func TestCreateDraftWithAudience(t *testing.T) {
@@ -137,7 +212,7 @@ func TestCreateDraftWithAudience(t *testing.T) {
 			})
 			defer srv.Close()
 
-			_, err := client.CreateDraft(model.DraftRequest{
+			_, err := client.CreateDraft(t.Context(), model.DraftRequest{
 				Title:    "Test",
 				Audience: aud,
 			})
@@ -150,3 +225,547 @@ func TestCreateDraftWithAudience(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadImage(t *testing.T) {
+	// 1x1 transparent PNG
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89" +
+		"\x00\x00\x00\rIDATx\x9cc\xf8\x0f\x00\x00\x01\x01\x00\x05\x18\xd8N\x00\x00\x00\x00IEND\xaeB`\x82")
+	var gotImage string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/image" || r.Method != http.MethodPost {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotImage = req["image"]
+		_ = json.NewEncoder(w).Encode(map[string]string{"url": "https://cdn.example.com/x.png"})
+	})
+	defer srv.Close()
+
+	upload, err := client.UploadImage(t.Context(), png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.URL != "https://cdn.example.com/x.png" {
+		t.Errorf("url = %q", upload.URL)
+	}
+	if upload.Width != 1 || upload.Height != 1 {
+		t.Errorf("dimensions = %dx%d, want 1x1", upload.Width, upload.Height)
+	}
+	if !strings.HasPrefix(gotImage, "data:image/png;base64,") {
+		t.Errorf("image payload = %.40q", gotImage)
+	}
+}
+
+func TestUploadImageRejectsNonImage(t *testing.T) {
+	client, srv := testClient(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("server should not be called")
+	})
+	defer srv.Close()
+
+	if _, err := client.UploadImage(t.Context(), []byte("plain text")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSetPostTags(t *testing.T) {
+	var created []string
+	var attached []string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/publication/post-tag" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode([]model.PostTag{{ID: "t1", Name: "Go"}})
+		case r.URL.Path == "/api/v1/publication/post-tag" && r.Method == http.MethodPost:
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req["name"])
+			_ = json.NewEncoder(w).Encode(model.PostTag{ID: "t2", Name: req["name"]})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/post/7/tag/"):
+			attached = append(attached, strings.TrimPrefix(r.URL.Path, "/api/v1/post/7/tag/"))
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	if err := client.SetPostTags(t.Context(), 7, []string{"go", "cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0] != "cli" {
+		t.Errorf("created = %v, want [cli]", created)
+	}
+	if len(attached) != 2 || attached[0] != "t1" || attached[1] != "t2" {
+		t.Errorf("attached = %v, want [t1 t2]", attached)
+	}
+}
+
+func TestSchedulePost(t *testing.T) {
+	var got map[string]any
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/drafts/5/schedule" || r.Method != http.MethodPost {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("{}"))
+	})
+	defer srv.Close()
+
+	at := time.Date(2030, 1, 2, 15, 4, 0, 0, time.FixedZone("EST", -5*60*60))
+	if err := client.SchedulePost(t.Context(), 5, at); err != nil {
+		t.Fatal(err)
+	}
+	if got["post_date"] != "2030-01-02T20:04:00Z" {
+		t.Errorf("post_date = %v", got["post_date"])
+	}
+
+	if err := client.UnschedulePost(t.Context(), 5); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := got["post_date"]; !ok || v != nil {
+		t.Errorf("unschedule post_date = %v, want null", v)
+	}
+}
+
+func TestListScheduled(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC()
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]model.DraftResponse{
+			{ID: 1, Title: "Unscheduled"},
+			{ID: 2, Title: "Later", PostDate: future.Add(time.Hour).Format(time.RFC3339)},
+			{ID: 3, Title: "Backdated", PostDate: "2020-01-01T00:00:00Z"},
+			{ID: 4, Title: "Sooner", PostDate: future.Format(time.RFC3339)},
+		})
+	})
+	defer srv.Close()
+
+	drafts, err := client.ListScheduled(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 2 || drafts[0].ID != 4 || drafts[1].ID != 2 {
+		t.Errorf("drafts = %+v", drafts)
+	}
+}
+
+func TestUpdateDraft(t *testing.T) {
+	var got map[string]any
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/drafts/9" || r.Method != http.MethodPut {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(model.DraftResponse{ID: 9, Title: "New"})
+	})
+	defer srv.Close()
+
+	resp, err := client.UpdateDraft(t.Context(), 9, model.DraftRequest{Title: "New", DraftBody: `{"type":"doc"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != 9 {
+		t.Errorf("id = %d", resp.ID)
+	}
+	if got["draft_title"] != "New" || got["draft_body"] != `{"type":"doc"}` {
+		t.Errorf("payload = %v", got)
+	}
+	if _, ok := got["draft_bylines"]; ok {
+		t.Error("draft_bylines should be omitted when empty")
+	}
+	if _, ok := got["type"]; ok {
+		t.Error("type should be omitted when empty")
+	}
+	if _, ok := got["section_chosen"]; ok {
+		t.Error("section_chosen should be omitted without a section")
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	called := false
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		_ = json.NewEncoder(w).Encode([]model.Post{})
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := client.ListPosts(ctx, api.ListOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if called {
+		t.Error("request sent despite canceled context")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		_ = json.NewEncoder(w).Encode([]model.Post{})
+	})
+	defer srv.Close()
+	defer close(release)
+
+	if client.HTTP.Timeout != api.DefaultRequestTimeout {
+		t.Errorf("default timeout = %v", client.HTTP.Timeout)
+	}
+	client.HTTP.Timeout = 50 * time.Millisecond
+	client.Retry.MaxAttempts = 1
+	start := time.Now()
+	if _, err := client.ListPosts(t.Context(), api.ListOptions{}); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v, timeout not applied", elapsed)
+	}
+}
+
+func fastRetries(client *api.Client, attempts int) {
+	client.Retry = api.RetryPolicy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	calls := 0
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]model.Post{{ID: 1}})
+	})
+	defer srv.Close()
+	fastRetries(client, 4)
+
+	posts, err := client.ListPosts(t.Context(), api.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || len(posts) != 1 {
+		t.Errorf("calls = %d, posts = %d", calls, len(posts))
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	calls := 0
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer srv.Close()
+	fastRetries(client, 2)
+
+	if _, err := client.ListPosts(t.Context(), api.ListOptions{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestNoRetryNonIdempotent(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantCalls int
+	}{
+		{http.StatusBadGateway, 1},
+		{http.StatusServiceUnavailable, 1},
+		{http.StatusTooManyRequests, 3},
+	}
+	ops := map[string]func(context.Context, *api.Client) error{
+		"create": func(ctx context.Context, c *api.Client) error {
+			_, err := c.CreateDraft(ctx, model.DraftRequest{DraftBylines: []model.Byline{{ID: 1}}})
+			return err
+		},
+		"publish": func(ctx context.Context, c *api.Client) error {
+			_, err := c.PublishDraft(ctx, 1, model.PublishOptions{SendEmail: true})
+			return err
+		},
+		"unpublish": func(ctx context.Context, c *api.Client) error {
+			return c.UnpublishPost(ctx, 1)
+		},
+	}
+	for name, op := range ops {
+		for _, tt := range tests {
+			t.Run(name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				calls := 0
+				client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+					calls++
+					w.WriteHeader(tt.status)
+				})
+				defer srv.Close()
+				fastRetries(client, 3)
+
+				if err := op(t.Context(), client); err == nil {
+					t.Fatal("expected error")
+				}
+				if calls != tt.wantCalls {
+					t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+				}
+			})
+		}
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	var bodies []string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(model.DraftResponse{ID: 9})
+	})
+	defer srv.Close()
+	fastRetries(client, 3)
+
+	if _, err := client.UpdateDraft(t.Context(), 9, model.DraftRequest{Title: "T"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] == "" {
+		t.Errorf("bodies = %q", bodies)
+	}
+}
+
+// pagedServer serves n posts with IDs 1..n, honoring offset and limit.
+func pagedServer(n int, requests *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		posts := []model.Post{}
+		for id := offset + 1; id <= n && len(posts) < limit; id++ {
+			posts = append(posts, model.Post{ID: id})
+		}
+		_ = json.NewEncoder(w).Encode(posts)
+	}
+}
+
+func TestListPostsPagination(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         api.ListOptions
+		wantFirst    int
+		wantCount    int
+		wantRequests int
+	}{
+		{"all", api.ListOptions{}, 1, 120, 3},
+		{"window", api.ListOptions{Offset: 10, Limit: 60}, 11, 60, 2},
+		{"past end", api.ListOptions{Offset: 110, Limit: 50}, 111, 10, 1},
+		{"exact page", api.ListOptions{Limit: api.PageSize}, 1, api.PageSize, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client, srv := testClient(pagedServer(120, &requests))
+			defer srv.Close()
+
+			posts, err := client.ListPosts(t.Context(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != tt.wantCount || posts[0].ID != tt.wantFirst {
+				t.Errorf("got %d posts starting at %d, want %d from %d", len(posts), posts[0].ID, tt.wantCount, tt.wantFirst)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestListSearchParam(t *testing.T) {
+	var got string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("search")
+		_ = json.NewEncoder(w).Encode([]model.DraftResponse{})
+	})
+	defer srv.Close()
+
+	if _, err := client.ListDrafts(t.Context(), api.ListOptions{Search: "go tips"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "go tips" {
+		t.Errorf("search = %q", got)
+	}
+}
+
+func TestPostsIteratorStopsEarly(t *testing.T) {
+	requests := 0
+	client, srv := testClient(pagedServer(500, &requests))
+	defer srv.Close()
+
+	count := 0
+	for p, err := range client.Posts(t.Context(), api.ListOptions{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+		if p.ID == 5 {
+			break
+		}
+	}
+	if count != 5 || requests != 1 {
+		t.Errorf("count = %d, requests = %d", count, requests)
+	}
+}
+
+func TestPaginationIgnoredOffset(t *testing.T) {
+	requests := 0
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		posts := make([]model.Post, api.PageSize)
+		for i := range posts {
+			posts[i].ID = i + 1
+		}
+		_ = json.NewEncoder(w).Encode(posts)
+	})
+	defer srv.Close()
+
+	posts, err := client.ListPosts(t.Context(), api.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != api.PageSize || requests != 2 {
+		t.Errorf("posts = %d, requests = %d", len(posts), requests)
+	}
+}
+
+func TestWhoami(t *testing.T) {
+	users := `[
+		{"id": 7, "name": "Editor", "role": "contributor"},
+		{"id": 9, "name": "Owner", "role": "admin", "publication": {"name": "The Letter", "subdomain": "letter"}},
+		{"id": 123, "name": "Me", "role": "contributor"}
+	]`
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/publication/users":
+			_, _ = w.Write([]byte(users))
+		case "/api/v1/user/profile/self":
+			_, _ = w.Write([]byte(`{"id": 7, "name": "Editor"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	u, err := client.Whoami(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 123 || u.Name != "Me" {
+		t.Errorf("user = %+v, want the account's own user 123", u)
+	}
+
+	// Without a stored user ID, the session's own user is reported, not the
+	// publication's admin.
+	client.Account.UserID = ""
+	u, err = client.Whoami(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 7 || u.Role != "contributor" {
+		t.Errorf("user = %+v, want the session's user 7", u)
+	}
+
+	client.Account.UserID = "42"
+	if _, err := client.Whoami(t.Context()); err == nil || !strings.Contains(err.Error(), "not a member") {
+		t.Errorf("err = %v, want not a member", err)
+	}
+}
+
+func TestWhoamiExpired(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Not authorized"}`))
+	})
+	defer srv.Close()
+
+	_, err := client.Whoami(t.Context())
+	if !api.IsUnauthorized(err) {
+		t.Errorf("err = %v, want unauthorized", err)
+	}
+}
+
+func TestWhoamiNoUsers(t *testing.T) {
+	client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	defer srv.Close()
+
+	if _, err := client.Whoami(t.Context()); err == nil {
+		t.Error("expected error for an empty user list")
+	}
+}
+
+func TestCookieRotation(t *testing.T) {
+	var seen []string
+	client, srv := testClient(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := r.Cookie("substack.sid")
+		seen = append(seen, c.Value)
+		if len(seen) == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "substack.sid", Value: "rotated", Path: "/"})
+		}
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	})
+	defer srv.Close()
+	var saved []model.Account
+	client.OnCookiesRotated = func(a model.Account) { saved = append(saved, a) }
+
+	for range 2 {
+		if _, err := client.GetPost(t.Context(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(seen) != 2 || seen[0] != "ssid-val" || seen[1] != "rotated" {
+		t.Errorf("substack.sid sent = %v, want [ssid-val rotated]", seen)
+	}
+	if client.Account.SubstackSID != "rotated" {
+		t.Errorf("account substack.sid = %q", client.Account.SubstackSID)
+	}
+	if len(saved) != 1 || saved[0].SubstackSID != "rotated" || saved[0].SID != "sid-val" {
+		t.Errorf("OnCookiesRotated calls = %+v", saved)
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		clear   bool
+		expired bool
+	}{
+		{"unauthorized", http.StatusUnauthorized, false, true},
+		{"forbidden with cleared session", http.StatusForbidden, true, true},
+		{"forbidden", http.StatusForbidden, false, false},
+		{"not found", http.StatusNotFound, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := testClient(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.clear {
+					http.SetCookie(w, &http.Cookie{Name: "substack.sid", Value: "", Path: "/", MaxAge: -1})
+				}
+				w.WriteHeader(tt.status)
+			})
+			defer srv.Close()
+
+			_, err := client.GetPost(t.Context(), 1)
+			if got := api.IsSessionExpired(err); got != tt.expired {
+				t.Fatalf("IsSessionExpired = %v, want %v (err: %v)", got, tt.expired, err)
+			}
+			var apiErr *api.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("err = %v, want an APIError with status %d", err, tt.status)
+			}
+			var sessionErr *api.SessionError
+			if tt.expired && (!errors.As(err, &sessionErr) || sessionErr.Account != "test") {
+				t.Errorf("err = %v, want a SessionError for account test", err)
+			}
+		})
+	}
+}
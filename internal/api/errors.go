@@ -0,0 +1,178 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxErrorBody caps how much of an unparseable error body is kept.
+const maxErrorBody = 200
+
+// APIError is returned for any response with a status of 400 or above.
+// Use errors.As to inspect it, or the Is* helpers for common cases.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string            // request path, without host or query
+	Message    string            // Substack's error message, or a trimmed raw body
+	Fields     map[string]string // per-field validation messages, if any
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API error %d", e.StatusCode)
+	if e.Method != "" || e.Endpoint != "" {
+		fmt.Fprintf(&b, " (%s %s)", e.Method, e.Endpoint)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if len(e.Fields) > 0 {
+		var parts []string
+		for _, k := range slices.Sorted(maps.Keys(e.Fields)) {
+			parts = append(parts, k+": "+e.Fields[k])
+		}
+		fmt.Fprintf(&b, " [%s]", strings.Join(parts, "; "))
+	}
+	return b.String()
+}
+
+// ErrSessionExpired matches errors caused by session cookies that Substack
+// no longer accepts.
+var ErrSessionExpired = errors.New("session expired")
+
+// SessionError is returned instead of a bare APIError when a request fails
+// because the account's session has expired: a 401, or a 403 that came with
+// the session cookie being deleted.
+type SessionError struct {
+	Account string
+	Err     *APIError
+}
+
+func (e *SessionError) Error() string {
+	return fmt.Sprintf("session for account %q has expired: %v", e.Account, e.Err)
+}
+
+func (e *SessionError) Unwrap() []error { return []error{ErrSessionExpired, e.Err} }
+
+// sessionError wraps err in a SessionError when it means the session has
+// expired; cleared tells whether the response deleted the session cookie.
+func (c *Client) sessionError(err *APIError, cleared bool) error {
+	if err.StatusCode == http.StatusUnauthorized || (err.StatusCode == http.StatusForbidden && cleared) {
+		return &SessionError{Account: c.Account.Name, Err: err}
+	}
+	return err
+}
+
+// newAPIError reads and closes resp.Body, parsing Substack's error payload.
+// Substack answers with {"error": "..."}, {"message": "..."} or
+// {"errors": [{"param": "...", "msg": "..."}]} depending on the endpoint.
+func newAPIError(resp *http.Response) *APIError {
+	defer func() { _ = resp.Body.Close() }()
+	raw, _ := io.ReadAll(resp.Body)
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	if e.RequestID == "" {
+		e.RequestID = resp.Header.Get("Cf-Ray")
+	}
+	if req := resp.Request; req != nil {
+		e.Method = req.Method
+		if req.URL != nil {
+			e.Endpoint = req.URL.Path
+		}
+	}
+
+	var payload struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Errors  []struct {
+			Param   string `json:"param"`
+			Field   string `json:"field"`
+			Msg     string `json:"msg"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(raw, &payload) == nil {
+		var msg string
+		if json.Unmarshal(payload.Error, &msg) == nil {
+			e.Message = msg
+		}
+		if e.Message == "" {
+			e.Message = payload.Message
+		}
+		for _, fe := range payload.Errors {
+			name := cmp.Or(fe.Param, fe.Field)
+			text := cmp.Or(fe.Msg, fe.Message)
+			if name == "" {
+				e.Message = cmp.Or(e.Message, text)
+				continue
+			}
+			if e.Fields == nil {
+				e.Fields = map[string]string{}
+			}
+			e.Fields[name] = text
+		}
+	} else if body := strings.TrimSpace(string(raw)); body != "" && !strings.HasPrefix(body, "<") {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody] + "..."
+		}
+		e.Message = body
+	}
+	if e.Message == "" && len(e.Fields) == 0 {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, c := range codes {
+		if apiErr.StatusCode == c {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSessionExpired reports whether err means the session cookies must be
+// renewed by logging in again.
+func IsSessionExpired(err error) bool {
+	return errors.Is(err, ErrSessionExpired)
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+
+// IsUnauthorized reports whether err is a 401, which Substack returns when
+// the session cookies are missing or expired.
+func IsUnauthorized(err error) bool { return hasStatus(err, http.StatusUnauthorized) }
+
+// IsForbidden reports whether err is a 403: signed in, but not allowed.
+func IsForbidden(err error) bool { return hasStatus(err, http.StatusForbidden) }
+
+// IsValidation reports whether Substack rejected the request body.
+func IsValidation(err error) bool {
+	return hasStatus(err, http.StatusBadRequest, http.StatusUnprocessableEntity)
+}
+
+// IsRateLimited reports whether err is a 429.
+func IsRateLimited(err error) bool { return hasStatus(err, http.StatusTooManyRequests) }
+
+// IsServerError reports whether err is a 5xx from the API.
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for dimension detection
+	_ "image/jpeg" // register JPEG decoder for dimension detection
+	_ "image/png"  // register PNG decoder for dimension detection
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// UploadImage uploads raw image bytes to Substack's media storage and returns
+// the hosted URL. Width and height are filled in locally when the API omits them.
+func (c *Client) UploadImage(ctx context.Context, data []byte) (*model.ImageUpload, error) {
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("unsupported image content type %q", contentType)
+	}
+	payload := map[string]string{
+		"image": fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data)),
+	}
+	url := fmt.Sprintf("%s/api/v1/image", c.baseURL())
+	resp, err := c.do(ctx, http.MethodPost, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	upload, err := decodeJSON[model.ImageUpload](resp)
+	if err != nil {
+		return nil, err
+	}
+	if upload.URL == "" {
+		return nil, errors.New("image upload returned no URL")
+	}
+	if upload.Width == 0 || upload.Height == 0 {
+		if cfg, _, decodeErr := image.DecodeConfig(bytes.NewReader(data)); decodeErr == nil {
+			upload.Width, upload.Height = cfg.Width, cfg.Height
+		}
+	}
+	if upload.Bytes == 0 {
+		upload.Bytes = len(data)
+	}
+	if upload.ContentType == "" {
+		upload.ContentType = contentType
+	}
+	return &upload, nil
+}
+
+// DownloadImage fetches a remote image. Session cookies are never sent, since
+// the URL usually points at a third-party host.
+func (c *Client) DownloadImage(ctx context.Context, url string) ([]byte, error) {
+	resp, err := c.send(ctx, true, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= httpBadRequestThreshold {
+		return nil, fmt.Errorf("downloading %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -32,7 +32,7 @@ func TestIntegration_CreateDraftWithAllAudiences(t *testing.T) {
 				Audience:  aud,
 			}
 
-			resp, err := client.CreateDraft(draft)
+			resp, err := client.CreateDraft(t.Context(), draft)
 			if err != nil {
 				t.Fatalf("CreateDraft(%s): %v", aud, err)
 			}
@@ -58,7 +58,7 @@ func TestIntegration_CreateDraftWithSection(t *testing.T) {
 		SectionChosen: false, // No section specified
 	}
 
-	resp, err := client.CreateDraft(draft)
+	resp, err := client.CreateDraft(t.Context(), draft)
 	if err != nil {
 		t.Fatalf("CreateDraft: %v", err)
 	}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// DefaultLoginURL is where Substack's email sign-in runs. EnvLoginURL
+// overrides it, e.g. to point at a local stand-in server.
+const (
+	DefaultLoginURL = "https://substack.com"
+	EnvLoginURL     = "SUBSTACK_LOGIN_URL"
+)
+
+// Endpoints of the email sign-in flow, relative to the login URL.
+const (
+	emailLoginPath    = "/api/v1/email-login"
+	emailCodePath     = "/api/v1/email-otp-login/complete"
+	profileSelfPath   = "/api/v1/user/profile/self"
+	loginRedirectPath = "/"
+)
+
+// EmailLogin signs in with the code or magic link Substack emails, and
+// collects the session cookies it sets.
+type EmailLogin struct {
+	BaseURL string
+	Email   string
+	HTTP    *http.Client
+}
+
+// NewEmailLogin starts an email sign-in for email against the login URL
+// from SUBSTACK_LOGIN_URL or DefaultLoginURL.
+func NewEmailLogin(email string) (*EmailLogin, error) {
+	base := os.Getenv(EnvLoginURL)
+	if base == "" {
+		base = DefaultLoginURL
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailLogin{
+		BaseURL: strings.TrimRight(base, "/"),
+		Email:   email,
+		HTTP:    &http.Client{Jar: jar, Timeout: DefaultRequestTimeout},
+	}, nil
+}
+
+func (l *EmailLogin) post(ctx context.Context, path string, body any) error {
+	resp, err := l.do(ctx, http.MethodPost, l.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (l *EmailLogin) do(ctx context.Context, method, target string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := l.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= httpBadRequestThreshold {
+		return nil, newAPIError(resp)
+	}
+	return resp, nil
+}
+
+// Request asks Substack to email a sign-in code and link.
+func (l *EmailLogin) Request(ctx context.Context) error {
+	return l.post(ctx, emailLoginPath, map[string]any{
+		"email":           l.Email,
+		"redirect":        loginRedirectPath,
+		"can_create_user": false,
+	})
+}
+
+// Complete finishes the sign-in with what the user copied from the email:
+// either the one-time code or the whole sign-in link.
+func (l *EmailLogin) Complete(ctx context.Context, codeOrLink string) error {
+	codeOrLink = strings.TrimSpace(codeOrLink)
+	if codeOrLink == "" {
+		return errors.New("no code or link given")
+	}
+	if !strings.Contains(codeOrLink, "://") {
+		return l.post(ctx, emailCodePath, map[string]string{
+			"email":    l.Email,
+			"code":     codeOrLink,
+			"redirect": loginRedirectPath,
+		})
+	}
+	link, err := url.Parse(codeOrLink)
+	if err != nil {
+		return fmt.Errorf("parsing sign-in link: %w", err)
+	}
+	base, _ := url.Parse(l.BaseURL)
+	if link.Host != base.Host && !strings.HasSuffix(link.Host, "."+base.Host) {
+		return fmt.Errorf("sign-in link points to %s, not %s", link.Host, base.Host)
+	}
+	resp, err := l.do(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Profile returns the signed-in user and the publications they belong to.
+func (l *EmailLogin) Profile(ctx context.Context) (*model.UserProfile, error) {
+	resp, err := l.do(ctx, http.MethodGet, l.BaseURL+profileSelfPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching profile: %w", err)
+	}
+	return ptr(decodeJSON[model.UserProfile](resp))
+}
+
+// Account returns an account carrying the session cookies collected so far.
+// Name, publication URL and user ID are left to the caller.
+func (l *EmailLogin) Account() (model.Account, error) {
+	base, err := url.Parse(l.BaseURL)
+	if err != nil {
+		return model.Account{}, err
+	}
+	var acct model.Account
+	for _, c := range l.HTTP.Jar.Cookies(base) {
+		switch c.Name {
+		case "connect.sid":
+			acct.SID = c.Value
+		case "substack.sid":
+			acct.SubstackSID = c.Value
+		case "substack.lli":
+			acct.SubstackLLI = c.Value
+		}
+	}
+	if acct.SID == "" && acct.SubstackSID == "" {
+		return acct, errors.New("sign-in did not return a session cookie")
+	}
+	return acct, nil
+}
+
+// PublicationURL returns the address of a publication: its custom domain
+// when it has one, otherwise its substack.com subdomain.
+func PublicationURL(p model.PublicationSummary) string {
+	if p.CustomDomain != "" {
+		return "https://" + p.CustomDomain
+	}
+	return "https://" + p.Subdomain + ".substack.com"
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/api"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// loginServer mimics Substack's email sign-in endpoints. Code 123456 and the
+// link with token=magic both sign in as user 42.
+func loginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	setSession := func(w http.ResponseWriter) {
+		for name, value := range map[string]string{"substack.sid": "s%3Anew", "substack.lli": "1", "connect.sid": "c"} {
+			http.SetCookie(w, &http.Cookie{Name: name, Value: value, Path: "/"})
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/email-login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["email"] != "you@example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"No account with that email"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /api/v1/email-otp-login/complete", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["code"] != "123456" || body["email"] != "you@example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Invalid code"}`))
+			return
+		}
+		setSession(w)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /api/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "magic" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		setSession(w)
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>home</html>"))
+	})
+	mux.HandleFunc("GET /api/v1/user/profile/self", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("substack.sid"); err != nil || c.Value != "s%3Anew" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 42, "name": "Jane", "handle": "jane", "publicationUsers": [
+			{"role": "admin", "publication": {"id": 1, "name": "Jane's Letter", "subdomain": "jane"}},
+			{"role": "contributor", "publication": {"id": 2, "name": "Shop", "subdomain": "shop",
+				"custom_domain": "shop.example.com"}}
+		]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	t.Setenv(api.EnvLoginURL, srv.URL)
+	return srv
+}
+
+func TestEmailLoginCode(t *testing.T) {
+	loginServer(t)
+	login, err := api.NewEmailLogin("you@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := login.Request(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := login.Complete(t.Context(), "000000"); !api.IsValidation(err) {
+		t.Errorf("wrong code: err = %v, want validation error", err)
+	}
+	if err := login.Complete(t.Context(), " 123456\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	acct, err := login.Account()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.SubstackSID != "s%3Anew" || acct.SubstackLLI != "1" || acct.SID != "c" {
+		t.Errorf("account = %+v", acct)
+	}
+	profile, err := login.Profile(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.ID != 42 || len(profile.PublicationUsers) != 2 || profile.PublicationUsers[0].Role != "admin" {
+		t.Errorf("profile = %+v", profile)
+	}
+}
+
+func TestEmailLoginLink(t *testing.T) {
+	srv := loginServer(t)
+	login, err := api.NewEmailLogin("you@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := login.Complete(t.Context(), "https://evil.example.com/api/v1/login?token=magic"); err == nil {
+		t.Error("expected error for a link to another host")
+	}
+	if _, err := login.Account(); err == nil {
+		t.Error("expected error before signing in")
+	}
+	if err := login.Complete(t.Context(), srv.URL+"/api/v1/login?token=magic"); err != nil {
+		t.Fatal(err)
+	}
+	if acct, err := login.Account(); err != nil || acct.SubstackSID != "s%3Anew" {
+		t.Errorf("account = %+v, %v", acct, err)
+	}
+}
+
+func TestEmailLoginUnknownEmail(t *testing.T) {
+	loginServer(t)
+	login, err := api.NewEmailLogin("nobody@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := login.Request(t.Context()); !api.IsValidation(err) {
+		t.Errorf("err = %v, want validation error", err)
+	}
+}
+
+func TestPublicationURL(t *testing.T) {
+	if got := api.PublicationURL(model.PublicationSummary{Subdomain: "jane"}); got != "https://jane.substack.com" {
+		t.Errorf("subdomain: %s", got)
+	}
+	custom := model.PublicationSummary{Subdomain: "shop", CustomDomain: "shop.example.com"}
+	if got := api.PublicationURL(custom); got != "https://shop.example.com" {
+		t.Errorf("custom domain: %s", got)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// PageSize is how many items are requested per page.
+const PageSize = 50
+
+// ListOptions selects a window of a listing. A zero Limit means no limit:
+// every item from Offset onwards is returned.
+type ListOptions struct {
+	Offset int
+	Limit  int
+
+	// Search is passed to Substack as a search hint. Endpoints that do not
+	// support it return everything, so callers must still filter.
+	Search string
+}
+
+// Posts iterates over published posts, fetching pages as needed. Iteration
+// stops after the first error, which is yielded with a zero post.
+func (c *Client) Posts(ctx context.Context, opts ListOptions) iter.Seq2[model.Post, error] {
+	return paginate(ctx, c, "/api/v1/posts/", opts, func(p model.Post) int { return p.ID })
+}
+
+// Drafts iterates over drafts like Posts.
+func (c *Client) Drafts(ctx context.Context, opts ListOptions) iter.Seq2[model.DraftResponse, error] {
+	return paginate(ctx, c, "/api/v1/drafts/", opts, func(d model.DraftResponse) int { return d.ID })
+}
+
+func paginate[T any](
+	ctx context.Context, c *Client, path string, opts ListOptions, id func(T) int,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		offset, remaining := opts.Offset, opts.Limit
+		seen := map[int]bool{}
+		for {
+			size := PageSize
+			if opts.Limit > 0 {
+				size = min(size, remaining)
+			}
+			query := url.Values{
+				"offset": {strconv.Itoa(offset)},
+				"limit":  {strconv.Itoa(size)},
+			}
+			if opts.Search != "" {
+				query.Set("search", opts.Search)
+			}
+			page, err := listPage[T](ctx, c, path, query)
+			if err != nil {
+				yield(zero, fmt.Errorf("listing %s at offset %d: %w", path, offset, err))
+				return
+			}
+			fresh := 0
+			for _, item := range page {
+				// An endpoint that ignores offset returns the same page
+				// forever; stop rather than yield duplicates.
+				if seen[id(item)] {
+					continue
+				}
+				seen[id(item)] = true
+				fresh++
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(page)
+			remaining -= fresh
+			if len(page) < size || fresh == 0 || (opts.Limit > 0 && remaining <= 0) {
+				return
+			}
+		}
+	}
+}
+
+func listPage[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.baseURL()+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return decodeJSON[[]T](resp)
+}
+
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := make([]T, 0)
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Idempotent requests
+// are retried on 429, 502, 503, 504 and network errors. Other requests,
+// including publishing and unpublishing, are retried only when the server
+// cannot have acted on them: a 429, or a connection that was never
+// established.
+type RetryPolicy struct {
+	MaxAttempts int           // total tries including the first; 1 disables retries
+	BaseDelay   time.Duration // first backoff, doubled on each retry
+	MaxDelay    time.Duration // cap on any single wait, including Retry-After
+}
+
+// DefaultRetryPolicy is used by NewClient and NewClientWith.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send issues the request built by newReq, retrying per c.Retry. newReq is
+// called once per attempt so the body can be replayed.
+func (c *Client) send(
+	ctx context.Context, idempotent bool, newReq func() (*http.Request, error),
+) (*http.Response, error) {
+	attempts := max(c.Retry.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.HTTP.Do(req)
+		if attempt >= attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !idempotent && !notConnected(err) {
+				return nil, err
+			}
+		case !retryableStatus(resp.StatusCode):
+			return resp, nil
+		case !idempotent && resp.StatusCode != http.StatusTooManyRequests:
+			return resp, nil
+		default:
+			wait = retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if wait <= 0 {
+			wait = c.backoff(attempt)
+		}
+		if c.Retry.MaxDelay > 0 {
+			wait = min(wait, c.Retry.MaxDelay)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns an exponential delay with full jitter for the given attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.Retry.BaseDelay << (attempt - 1)
+	if d <= 0 || (c.Retry.MaxDelay > 0 && d > c.Retry.MaxDelay) {
+		d = c.Retry.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// notConnected reports whether err happened before a connection existed, in
+// which case the server never saw the request.
+func notConnected(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
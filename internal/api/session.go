@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// Whoami checks the account's session with authenticated requests and
+// returns the user it belongs to, with their role on the publication. The
+// user is Account.UserID when that is set, otherwise the one the profile
+// endpoint reports for the session; the publication users endpoint only adds
+// role and publication.
+func (c *Client) Whoami(ctx context.Context) (*model.PublicationUser, error) {
+	id := c.Account.UserID
+	if id == "" {
+		profile, err := c.profile(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id = strconv.Itoa(profile.ID)
+	}
+	url := fmt.Sprintf("%s/api/v1/publication/users", c.baseURL())
+	resp, err := c.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching publication users: %w", err)
+	}
+	users, err := decodeJSON[[]model.PublicationUser](resp)
+	if err != nil {
+		return nil, fmt.Errorf("decoding publication users: %w", err)
+	}
+	for _, u := range users {
+		if strconv.Itoa(u.ID) == id {
+			return &u, nil
+		}
+	}
+	return nil, fmt.Errorf("user %s is not a member of %s", id, c.Account.PublicationURL)
+}
+
+// profile returns the user the session cookies belong to.
+func (c *Client) profile(ctx context.Context) (*model.UserProfile, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.baseURL()+profileSelfPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching profile: %w", err)
+	}
+	profile, err := decodeJSON[model.UserProfile](resp)
+	if err != nil {
+		return nil, fmt.Errorf("decoding profile: %w", err)
+	}
+	if profile.ID == 0 {
+		return nil, errors.New("profile has no user ID")
+	}
+	return &profile, nil
+}
+
+// Session cookie names.
+const (
+	cookieSID         = "connect.sid"
+	cookieSubstackSID = "substack.sid"
+	cookieSubstackLLI = "substack.lli"
+)
+
+// sessionJar is the client's cookie jar. It sends the account's session
+// cookies to the publication and substack.com, and takes rotated values
+// from Set-Cookie, including on redirects, straight into the account.
+type sessionJar struct {
+	mu      sync.Mutex
+	client  *Client
+	cleared bool // the server deleted the session cookie
+}
+
+func (j *sessionJar) fields() map[string]*string {
+	a := j.client.Account
+	return map[string]*string{cookieSID: &a.SID, cookieSubstackSID: &a.SubstackSID, cookieSubstackLLI: &a.SubstackLLI}
+}
+
+// sendsTo reports whether session cookies belong on requests to u.
+func (j *sessionJar) sendsTo(u *url.URL) bool {
+	host := u.Hostname()
+	if pub, err := url.Parse(j.client.Account.PublicationURL); err == nil && host == pub.Hostname() {
+		return true
+	}
+	return host == "substack.com" || strings.HasSuffix(host, ".substack.com")
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	if !j.sendsTo(u) {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var cookies []*http.Cookie
+	for _, name := range []string{cookieSubstackSID, cookieSubstackLLI, cookieSID} {
+		if v := *j.fields()[name]; v != "" {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: v})
+		}
+	}
+	return cookies
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if !j.sendsTo(u) {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	changed := false
+	for _, c := range cookies {
+		field, ok := j.fields()[c.Name]
+		if !ok {
+			continue
+		}
+		if c.MaxAge < 0 || c.Value == "" || (!c.Expires.IsZero() && c.Expires.Before(time.Now())) {
+			if c.Name == cookieSubstackSID || c.Name == cookieSID {
+				j.cleared = true
+			}
+			continue
+		}
+		if *field != c.Value {
+			*field, changed = c.Value, true
+		}
+	}
+	if changed && j.client.OnCookiesRotated != nil {
+		j.client.OnCookiesRotated(*j.client.Account)
+	}
+}
+
+// sessionCleared reports and resets whether the server has deleted the
+// session cookie since the last call.
+func (j *sessionJar) sessionCleared() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	cleared := j.cleared
+	j.cleared = false
+	return cleared
+}
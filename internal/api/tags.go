@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+func (c *Client) ListTags(ctx context.Context) ([]model.PostTag, error) {
+	url := fmt.Sprintf("%s/api/v1/publication/post-tag", c.baseURL())
+	resp, err := c.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return decodeJSON[[]model.PostTag](resp)
+}
+
+func (c *Client) CreateTag(ctx context.Context, name string) (*model.PostTag, error) {
+	url := fmt.Sprintf("%s/api/v1/publication/post-tag", c.baseURL())
+	resp, err := c.do(ctx, http.MethodPost, url, map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return ptr(decodeJSON[model.PostTag](resp))
+}
+
+// SetPostTags attaches the named tags to a post or draft, creating any tags
+// the publication does not have yet. Names match case-insensitively.
+func (c *Client) SetPostTags(ctx context.Context, id int, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	existing, err := c.ListTags(ctx)
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+	byName := map[string]model.PostTag{}
+	for _, t := range existing {
+		byName[strings.ToLower(t.Name)] = t
+	}
+	for _, name := range names {
+		tag, ok := byName[strings.ToLower(name)]
+		if !ok {
+			created, createErr := c.CreateTag(ctx, name)
+			if createErr != nil {
+				return fmt.Errorf("creating tag %q: %w", name, createErr)
+			}
+			tag = *created
+			byName[strings.ToLower(name)] = tag
+		}
+		url := fmt.Sprintf("%s/api/v1/post/%d/tag/%s", c.baseURL(), id, tag.ID)
+		resp, attachErr := c.doIdempotent(ctx, http.MethodPost, url, nil)
+		if attachErr != nil {
+			return fmt.Errorf("attaching tag %q: %w", name, attachErr)
+		}
+		_ = resp.Body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
+)
+
+// EnvPassphrase supplies the passphrase of the encrypted secrets file
+// without a prompt.
+const EnvPassphrase = "SUBSTACK_PASSPHRASE"
+
+const (
+	secretsFileName = "secrets.enc"
+	secretsVersion  = 1
+	saltSize        = 16
+	keySize         = 32
+)
+
+// defaultKDFIterations is the PBKDF2-SHA256 work factor for newly written
+// files unless the Store sets its own.
+const defaultKDFIterations = 600_000
+
+// encryptedSecrets is the on-disk form of the file backend: a JSON map of
+// account name to Secrets, sealed with AES-256-GCM under a key derived from
+// the passphrase. Salt and nonce are fresh on every write.
+type encryptedSecrets struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// fileBackend stores secrets in a passphrase-encrypted file next to
+// accounts.json. The passphrase is cached in the Store, so a load followed
+// by a save prompts once.
+type fileBackend struct {
+	path  string
+	store *Store
+}
+
+func (f *fileBackend) passphrase(confirm bool) (string, error) {
+	if f.store.passphrase != "" {
+		return f.store.passphrase, nil
+	}
+	p := os.Getenv(EnvPassphrase)
+	if p == "" {
+		if f.store.Prompt == nil {
+			return "", fmt.Errorf("secrets are encrypted; set %s", EnvPassphrase)
+		}
+		var err error
+		if p, err = f.store.Prompt(confirm); err != nil {
+			return "", err
+		}
+	}
+	if p == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+	f.store.passphrase = p
+	return p, nil
+}
+
+func (f *fileBackend) iterations() int {
+	if f.store.KDFIterations > 0 {
+		return f.store.KDFIterations
+	}
+	return defaultKDFIterations
+}
+
+func (f *fileBackend) read() (map[string]Secrets, error) {
+	raw, readErr := os.ReadFile(f.path)
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return map[string]Secrets{}, nil
+		}
+		return nil, readErr
+	}
+	var enc encryptedSecrets
+	if unmarshalErr := json.Unmarshal(raw, &enc); unmarshalErr != nil {
+		return nil, fmt.Errorf("parsing %s: %w", f.path, unmarshalErr)
+	}
+	if enc.Version != secretsVersion || enc.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("%s: unsupported format version %d (%s)", f.path, enc.Version, enc.KDF)
+	}
+	pass, err := f.passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(pass, enc.Salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, openErr := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+	if openErr != nil {
+		f.store.passphrase = ""
+		return nil, fmt.Errorf("decrypting %s: wrong passphrase or corrupted file", f.path)
+	}
+	secrets := map[string]Secrets{}
+	if unmarshalErr := json.Unmarshal(plain, &secrets); unmarshalErr != nil {
+		return nil, fmt.Errorf("decoding %s: %w", f.path, unmarshalErr)
+	}
+	return secrets, nil
+}
+
+func (f *fileBackend) write(secrets map[string]Secrets) error {
+	_, statErr := os.Stat(f.path)
+	pass, err := f.passphrase(os.IsNotExist(statErr))
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	enc := encryptedSecrets{Version: secretsVersion, KDF: "pbkdf2-sha256", Iterations: f.iterations()}
+	enc.Salt = make([]byte, saltSize)
+	_, _ = rand.Read(enc.Salt)
+	gcm, err := newGCM(pass, enc.Salt, enc.Iterations)
+	if err != nil {
+		return err
+	}
+	enc.Nonce = make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(enc.Nonce)
+	enc.Data = gcm.Seal(nil, enc.Nonce, plain, nil)
+	raw, err := json.MarshalIndent(enc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filestore.WriteAtomic(f.path, raw, 0600)
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (f *fileBackend) Load(names []string) (map[string]Secrets, error) {
+	all, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]Secrets, len(names))
+	for _, name := range names {
+		if s, ok := all[name]; ok {
+			secrets[name] = s
+		}
+	}
+	return secrets, nil
+}
+
+// Save rewrites the file with exactly the given secrets, which drops removed
+// accounts along the way.
+func (f *fileBackend) Save(secrets map[string]Secrets, _, _ []string) error {
+	return f.write(secrets)
+}
+
+func (f *fileBackend) Clear(_ []string) error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// Environment variables that supply credentials without an account store,
+// for CI runners where interactive login is not an option.
+const (
+	EnvPublicationURL  = "SUBSTACK_PUBLICATION_URL"
+	EnvSID             = "SUBSTACK_SID"
+	EnvSubstackSID     = "SUBSTACK_SUBSTACK_SID"
+	EnvSubstackLLI     = "SUBSTACK_SUBSTACK_LLI"
+	EnvCredentialsFile = "SUBSTACK_CREDENTIALS_FILE"
+)
+
+// EnvAccountName names the ephemeral account built from the environment
+// when the credentials file does not give one.
+const EnvAccountName = "env"
+
+// FromEnv builds an account from SUBSTACK_CREDENTIALS_FILE, a JSON object in
+// the accounts.json account format, and then the individual SUBSTACK_*
+// variables, which override fields from the file. ok is false when none of
+// them are set.
+func FromEnv() (model.Account, bool, error) {
+	var acct model.Account
+	found := false
+	if path := os.Getenv(EnvCredentialsFile); path != "" {
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return model.Account{}, false, fmt.Errorf("reading %s: %w", EnvCredentialsFile, readErr)
+		}
+		if unmarshalErr := json.Unmarshal(data, &acct); unmarshalErr != nil {
+			return model.Account{}, false, fmt.Errorf("parsing %s: %w", path, unmarshalErr)
+		}
+		found = true
+	}
+	for env, field := range map[string]*string{
+		EnvPublicationURL: &acct.PublicationURL,
+		EnvSID:            &acct.SID,
+		EnvSubstackSID:    &acct.SubstackSID,
+		EnvSubstackLLI:    &acct.SubstackLLI,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+			found = true
+		}
+	}
+	if !found {
+		return model.Account{}, false, nil
+	}
+	if acct.PublicationURL == "" {
+		return model.Account{}, false, errors.New("credentials from the environment are missing " + EnvPublicationURL)
+	}
+	if acct.SID == "" && acct.SubstackSID == "" {
+		return model.Account{}, false, fmt.Errorf("credentials from the environment need %s or %s", EnvSID, EnvSubstackSID)
+	}
+	if acct.Name == "" {
+		acct.Name = EnvAccountName
+	}
+	NormalizeAccount(&acct)
+	if err := ValidateAccount(acct); err != nil {
+		return model.Account{}, false, fmt.Errorf("credentials from the environment: %w", err)
+	}
+	return acct, true, nil
+}
+
+// Resolve picks the account for one invocation. A non-empty name selects
+// that stored account; otherwise credentials from the environment win over
+// the store's active account. The store itself is never modified.
+func (s *Store) Resolve(name string) (*model.Account, error) {
+	if name == "" {
+		acct, ok, err := FromEnv()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &acct, nil
+		}
+	}
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Select(store, name)
+}
+
+// ResolveName returns the name of the account Resolve would pick, or "" when
+// there is none. Unlike Resolve it never reads secrets, so it cannot prompt.
+func (s *Store) ResolveName(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	acct, ok, err := FromEnv()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return acct.Name, nil
+	}
+	store, err := readStore(s.Path)
+	if err != nil {
+		return "", err
+	}
+	return store.Active, nil
+}
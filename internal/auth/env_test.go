@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// clearEnv unsets every credential variable for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{EnvPublicationURL, EnvSID, EnvSubstackSID, EnvSubstackLLI, EnvCredentialsFile} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestFromEnvUnset(t *testing.T) {
+	clearEnv(t)
+	acct, ok, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("acct = %+v, want none", acct)
+	}
+}
+
+func TestFromEnvVariables(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPublicationURL, "https://ci.substack.com")
+	t.Setenv(EnvSubstackSID, "s%3Aabc")
+
+	acct, ok, err := FromEnv()
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if acct.Name != EnvAccountName || acct.PublicationURL != "https://ci.substack.com" || acct.SubstackSID != "s%3Aabc" {
+		t.Errorf("acct = %+v", acct)
+	}
+}
+
+func TestFromEnvCredentialsFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "creds.json")
+	data := `{"name":"ci","publication_url":"https://file.substack.com","sid":"file-sid","substack_lli":"lli"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvCredentialsFile, path)
+	t.Setenv(EnvSID, "env-sid")
+
+	acct, ok, err := FromEnv()
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if acct.Name != "ci" || acct.PublicationURL != "https://file.substack.com" || acct.SubstackLLI != "lli" {
+		t.Errorf("acct = %+v", acct)
+	}
+	if acct.SID != "env-sid" {
+		t.Errorf("sid = %q, want the variable to override the file", acct.SID)
+	}
+}
+
+func TestFromEnvIncomplete(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvSID, "sid")
+	if _, _, err := FromEnv(); err == nil {
+		t.Error("expected error without a publication URL")
+	}
+
+	t.Setenv(EnvPublicationURL, "https://ci.substack.com")
+	t.Setenv(EnvSID, "")
+	if _, _, err := FromEnv(); err == nil {
+		t.Error("expected error without a session cookie")
+	}
+
+	t.Setenv(EnvCredentialsFile, filepath.Join(t.TempDir(), "missing.json"))
+	if _, _, err := FromEnv(); err == nil {
+		t.Error("expected error for a missing credentials file")
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	clearEnv(t)
+	s := tmpStore(t)
+	store := &model.AccountStore{}
+	AddAccount(store, model.Account{Name: "stored", PublicationURL: "https://stored.substack.com"})
+	if err := s.Save(store); err != nil {
+		t.Fatal(err)
+	}
+
+	acct, err := s.Resolve("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Name != "stored" {
+		t.Errorf("without env: name = %q, want stored", acct.Name)
+	}
+
+	t.Setenv(EnvPublicationURL, "https://ci.substack.com")
+	t.Setenv(EnvSID, "sid")
+	acct, err = s.Resolve("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Name != EnvAccountName {
+		t.Errorf("with env: name = %q, want %s", acct.Name, EnvAccountName)
+	}
+
+	acct, err = s.Resolve("stored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Name != "stored" {
+		t.Errorf("named account: name = %q, want stored", acct.Name)
+	}
+}
+
+func TestResolveName(t *testing.T) {
+	clearEnv(t)
+	s := tmpStore(t)
+	if name, err := s.ResolveName(""); err != nil || name != "" {
+		t.Errorf("empty store: %q, %v", name, err)
+	}
+	store := &model.AccountStore{}
+	AddAccount(store, model.Account{Name: "stored", PublicationURL: "https://stored.substack.com"})
+	if err := s.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := s.ResolveName(""); err != nil || name != "stored" {
+		t.Errorf("active: %q, %v", name, err)
+	}
+	t.Setenv(EnvPublicationURL, "https://ci.substack.com")
+	t.Setenv(EnvSID, "sid")
+	if name, err := s.ResolveName(""); err != nil || name != EnvAccountName {
+		t.Errorf("env: %q, %v", name, err)
+	}
+	if name, err := s.ResolveName("other"); err != nil || name != "other" {
+		t.Errorf("named: %q, %v", name, err)
+	}
+}
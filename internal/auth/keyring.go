@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name account secrets are filed under; each
+// account is stored as one JSON-encoded Secrets item named after it.
+const keyringService = "substack-cli"
+
+// keyringServiceFor returns the service name for the store in a relocated
+// config directory: keyringService suffixed with a short hash of the
+// directory's absolute path.
+func keyringServiceFor(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return keyringService + "-" + hex.EncodeToString(sum[:6])
+}
+
+// keyringBackend stores secrets in the system keyring: the Secret Service
+// D-Bus API on Linux, the Keychain on macOS and the Credential Manager on
+// Windows.
+type keyringBackend struct {
+	service string
+}
+
+// KeyringAvailable reports whether a system keyring answers.
+func KeyringAvailable() bool {
+	_, err := keyring.Get(keyringService, "availability-probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (k keyringBackend) Load(names []string) (map[string]Secrets, error) {
+	secrets := make(map[string]Secrets, len(names))
+	for _, name := range names {
+		raw, err := keyring.Get(k.service, name)
+		if errors.Is(err, keyring.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var s Secrets
+		if unmarshalErr := json.Unmarshal([]byte(raw), &s); unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+		secrets[name] = s
+	}
+	return secrets, nil
+}
+
+// Save writes only the changed accounts' items.
+func (k keyringBackend) Save(secrets map[string]Secrets, changed, removed []string) error {
+	for _, name := range changed {
+		raw, err := json.Marshal(secrets[name])
+		if err != nil {
+			return err
+		}
+		if setErr := keyring.Set(k.service, name, string(raw)); setErr != nil {
+			return setErr
+		}
+	}
+	return k.Clear(removed)
+}
+
+func (k keyringBackend) Clear(names []string) error {
+	for _, name := range names {
+		if err := keyring.Delete(k.service, name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
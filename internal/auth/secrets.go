@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// Secret backend names. Plaintext keeps cookies in accounts.json itself.
+const (
+	BackendKeyring   = "keyring"
+	BackendFile      = "file"
+	BackendPlaintext = "plaintext"
+)
+
+// Backends lists the secret backends in order of preference.
+func Backends() []string {
+	return []string{BackendKeyring, BackendFile, BackendPlaintext}
+}
+
+// EnvSecretBackend overrides DefaultBackend's choice for new stores.
+const EnvSecretBackend = "SUBSTACK_SECRET_BACKEND"
+
+// Secrets are the cookies of one account: the part a backend keeps.
+type Secrets struct {
+	SID         string `json:"sid"`
+	SubstackSID string `json:"substack_sid"`
+	SubstackLLI string `json:"substack_lli"`
+}
+
+func secretsOf(a model.Account) Secrets {
+	return Secrets{SID: a.SID, SubstackSID: a.SubstackSID, SubstackLLI: a.SubstackLLI}
+}
+
+func (s Secrets) apply(a *model.Account) {
+	a.SID, a.SubstackSID, a.SubstackLLI = s.SID, s.SubstackSID, s.SubstackLLI
+}
+
+// SecretBackend keeps account cookies outside accounts.json.
+type SecretBackend interface {
+	// Load returns the secrets of the named accounts, leaving out any it
+	// does not have.
+	Load(names []string) (map[string]Secrets, error)
+	// Save stores the secrets of every current account and forgets the
+	// removed ones. Only the changed accounts' secrets differ from what the
+	// backend holds, so a backend with one item per account may skip the rest.
+	Save(secrets map[string]Secrets, changed, removed []string) error
+	// Clear forgets the named accounts' secrets after a move to another
+	// backend.
+	Clear(names []string) error
+}
+
+// ValidBackend reports whether name is a known secret backend.
+func ValidBackend(name string) error {
+	if !slices.Contains(Backends(), name) {
+		return fmt.Errorf("unknown secret backend %q (use %s)", name, strings.Join(Backends(), ", "))
+	}
+	return nil
+}
+
+// DefaultBackend picks the backend for a new store: SUBSTACK_SECRET_BACKEND
+// when set, else the system keyring when one is reachable, else the
+// passphrase-encrypted file. Plaintext is only ever chosen explicitly.
+func DefaultBackend() (string, error) {
+	if name := os.Getenv(EnvSecretBackend); name != "" {
+		return name, ValidBackend(name)
+	}
+	if KeyringAvailable() {
+		return BackendKeyring, nil
+	}
+	return BackendFile, nil
+}
+
+// openBackend returns the named backend for the store. ok is false for
+// plaintext, where cookies stay in the store file.
+func (s *Store) openBackend(name string) (SecretBackend, bool, error) {
+	switch name {
+	case "", BackendPlaintext:
+		return nil, false, nil
+	case BackendKeyring:
+		service := s.KeyringService
+		if service == "" {
+			service = keyringService
+		}
+		return keyringBackend{service: service}, true, nil
+	case BackendFile:
+		return &fileBackend{path: filepath.Join(filepath.Dir(s.Path), secretsFileName), store: s}, true, nil
+	}
+	return nil, false, ValidBackend(name)
+}
+
+func accountNames(accounts []model.Account) []string {
+	names := make([]string, len(accounts))
+	for i, a := range accounts {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// loadSecrets fills in the cookies of every account from the store's backend.
+func (s *Store) loadSecrets(store *model.AccountStore) error {
+	backend, ok, err := s.openBackend(store.SecretBackend)
+	if err != nil || !ok || len(store.Accounts) == 0 {
+		return err
+	}
+	secrets, err := backend.Load(accountNames(store.Accounts))
+	if err != nil {
+		return fmt.Errorf("loading secrets from %s: %w", store.SecretBackend, err)
+	}
+	for i := range store.Accounts {
+		if sec, found := secrets[store.Accounts[i].Name]; found {
+			sec.apply(&store.Accounts[i])
+		}
+	}
+	return nil
+}
+
+// saveSecrets hands the cookies to the store's backend and returns the copy
+// of the store to write to disk, without them. Accounts in previous but no
+// longer in store are forgotten by the backend. loaded holds the secrets as
+// the backend has them, when known, so unchanged ones need not be written.
+func (s *Store) saveSecrets(
+	store *model.AccountStore, previous []string, loaded map[string]Secrets,
+) (*model.AccountStore, error) {
+	backend, ok, err := s.openBackend(store.SecretBackend)
+	if err != nil || !ok {
+		return store, err
+	}
+	stripped := *store
+	stripped.Accounts = slices.Clone(store.Accounts)
+	secrets := make(map[string]Secrets, len(store.Accounts))
+	var changed []string
+	for i := range stripped.Accounts {
+		name, sec := stripped.Accounts[i].Name, secretsOf(stripped.Accounts[i])
+		secrets[name] = sec
+		if old, known := loaded[name]; !known || old != sec {
+			changed = append(changed, name)
+		}
+		Secrets{}.apply(&stripped.Accounts[i])
+	}
+	var removed []string
+	for _, name := range previous {
+		if _, kept := secrets[name]; !kept {
+			removed = append(removed, name)
+		}
+	}
+	if saveErr := backend.Save(secrets, changed, removed); saveErr != nil {
+		return nil, fmt.Errorf("saving secrets to %s: %w", store.SecretBackend, saveErr)
+	}
+	return &stripped, nil
+}
+
+// Migrate moves the cookies of every stored account to the named backend and
+// clears them from the old one. It returns the old backend's name.
+func (s *Store) Migrate(to string) (string, error) {
+	if err := ValidBackend(to); err != nil {
+		return "", err
+	}
+	unlock, err := filestore.Lock(s.Path)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	store, err := s.Load()
+	if err != nil {
+		return "", err
+	}
+	from := store.SecretBackend
+	if from == "" {
+		from = BackendPlaintext
+	}
+	if from == to {
+		if store.SecretBackend == "" {
+			// Record the choice, so plaintext is no longer just a default.
+			store.SecretBackend = to
+			return from, s.save(store, nil)
+		}
+		return from, nil
+	}
+	if len(store.Accounts) == 0 {
+		return from, errors.New("no accounts to migrate")
+	}
+	old, hasOld, err := s.openBackend(from)
+	if err != nil {
+		return from, err
+	}
+	store.SecretBackend = to
+	if saveErr := s.save(store, nil); saveErr != nil {
+		return from, saveErr
+	}
+	if hasOld {
+		if clearErr := old.Clear(accountNames(store.Accounts)); clearErr != nil {
+			return from, fmt.Errorf("accounts moved to %s, but clearing %s failed: %w", to, from, clearErr)
+		}
+	}
+	return from, nil
+}
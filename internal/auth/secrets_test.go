@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"github.com/zalando/go-keyring"
+)
+
+// secretStore returns a store in a fresh directory, with a cheap KDF, a known
+// passphrase and an in-memory keyring.
+func secretStore(t *testing.T) *Store {
+	t.Helper()
+	keyring.MockInit()
+	t.Setenv(EnvPassphrase, "correct horse")
+	s := tmpStore(t)
+	s.KDFIterations = 1000
+	return s
+}
+
+func storeWithCookies(backend string) *model.AccountStore {
+	store := &model.AccountStore{SecretBackend: backend}
+	AddAccount(store, model.Account{
+		Name: "a", PublicationURL: "https://a.substack.com", SID: "sid-a", SubstackSID: "ssid-a", SubstackLLI: "lli-a",
+	})
+	AddAccount(store, model.Account{Name: "b", PublicationURL: "https://b.substack.com", SubstackSID: "ssid-b"})
+	return store
+}
+
+// assertNoCookies fails if any cookie value appears in the file at path.
+func assertNoCookies(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"sid-a", "ssid-a", "lli-a", "ssid-b"} {
+		if strings.Contains(string(data), v) {
+			t.Errorf("%s contains cookie %q", filepath.Base(path), v)
+		}
+	}
+}
+
+func assertCookies(t *testing.T, s *Store) {
+	t.Helper()
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := GetAccount(loaded, "a")
+	b, _ := GetAccount(loaded, "b")
+	if a == nil || a.SID != "sid-a" || a.SubstackSID != "ssid-a" || a.SubstackLLI != "lli-a" {
+		t.Errorf("account a = %+v", a)
+	}
+	if b == nil || b.SubstackSID != "ssid-b" {
+		t.Errorf("account b = %+v", b)
+	}
+}
+
+func TestSecretBackendsRoundTrip(t *testing.T) {
+	for _, backend := range []string{BackendKeyring, BackendFile} {
+		t.Run(backend, func(t *testing.T) {
+			s := secretStore(t)
+			if err := s.Save(storeWithCookies(backend)); err != nil {
+				t.Fatal(err)
+			}
+			assertNoCookies(t, s.Path)
+			assertCookies(t, s)
+		})
+	}
+}
+
+func TestFileBackendEncrypted(t *testing.T) {
+	s := secretStore(t)
+	if err := s.Save(storeWithCookies(BackendFile)); err != nil {
+		t.Fatal(err)
+	}
+	assertNoCookies(t, filepath.Join(filepath.Dir(s.Path), secretsFileName))
+
+	s.passphrase = ""
+	t.Setenv(EnvPassphrase, "wrong")
+	if _, err := s.Load(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("err = %v, want wrong passphrase", err)
+	}
+
+	s.passphrase = ""
+	t.Setenv(EnvPassphrase, "")
+	if _, err := s.Load(); err == nil {
+		t.Error("expected error without a passphrase")
+	}
+
+	s.Prompt = func(bool) (string, error) { return "correct horse", nil }
+	assertCookies(t, s)
+}
+
+func TestSaveForgetsRemovedAccounts(t *testing.T) {
+	s := secretStore(t)
+	store := storeWithCookies(BackendKeyring)
+	if err := s.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveAccount(store, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Get(keyringService, "b"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("keyring still has b: %v", err)
+	}
+}
+
+func TestKeyringServiceIsolatesStores(t *testing.T) {
+	shared := secretStore(t)
+	if err := shared.Save(storeWithCookies(BackendKeyring)); err != nil {
+		t.Fatal(err)
+	}
+	isolated := tmpStore(t)
+	isolated.KeyringService = keyringServiceFor(filepath.Dir(isolated.Path))
+	store := &model.AccountStore{SecretBackend: BackendKeyring}
+	AddAccount(store, model.Account{Name: "a", PublicationURL: "https://a.substack.com", SID: "other"})
+	if err := isolated.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	assertCookies(t, shared)
+}
+
+func TestUpdateWritesOnlyChangedSecrets(t *testing.T) {
+	s := secretStore(t)
+	if err := s.Save(storeWithCookies(BackendKeyring)); err != nil {
+		t.Fatal(err)
+	}
+	// Drop b's item behind the store's back: an Update that rewrote every
+	// account would put it back.
+	if err := keyring.Delete(keyringService, "b"); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Update(func(store *model.AccountStore) error {
+		store.Accounts[0].SubstackSID = "rotated"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Get(keyringService, "b"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("unchanged account b was rewritten: %v", err)
+	}
+	if raw, err := keyring.Get(keyringService, "a"); err != nil || !strings.Contains(raw, "rotated") {
+		t.Errorf("a = %q, %v; want the rotated cookie", raw, err)
+	}
+}
+
+func TestSetActiveLeavesSecrets(t *testing.T) {
+	s := secretStore(t)
+	if err := s.Save(storeWithCookies(BackendFile)); err != nil {
+		t.Fatal(err)
+	}
+	// Switching needs no passphrase.
+	fresh := &Store{Path: s.Path}
+	t.Setenv(EnvPassphrase, "")
+	if err := fresh.SetActive("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fresh.SetActive("nope"); err == nil {
+		t.Error("expected error for unknown account")
+	}
+	t.Setenv(EnvPassphrase, "correct horse")
+	assertCookies(t, fresh)
+	if loaded, err := fresh.Load(); err != nil || loaded.Active != "b" {
+		t.Errorf("active = %v, %v; want b", loaded, err)
+	}
+}
+
+func TestWarnPlaintext(t *testing.T) {
+	s := secretStore(t)
+	if err := s.Save(storeWithCookies("")); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []string
+	s.Warn = func(msg string) { warnings = append(warnings, msg) }
+	for range 2 {
+		if _, err := s.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "plaintext") {
+		t.Errorf("warnings = %q, want one about plaintext", warnings)
+	}
+
+	// Choosing plaintext explicitly silences the warning.
+	if _, err := s.Migrate(BackendPlaintext); err != nil {
+		t.Fatal(err)
+	}
+	warnings = nil
+	explicit := &Store{Path: s.Path, Warn: s.Warn}
+	if _, err := explicit.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings after opting in = %q", warnings)
+	}
+	assertCookies(t, explicit)
+}
+
+func TestMigrate(t *testing.T) {
+	s := secretStore(t)
+	if err := s.Save(storeWithCookies("")); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := filepath.Join(filepath.Dir(s.Path), secretsFileName)
+
+	for _, step := range []struct{ from, to string }{
+		{BackendPlaintext, BackendFile},
+		{BackendFile, BackendKeyring},
+		{BackendKeyring, BackendPlaintext},
+	} {
+		from, err := s.Migrate(step.to)
+		if err != nil {
+			t.Fatalf("%s -> %s: %v", step.from, step.to, err)
+		}
+		if from != step.from {
+			t.Errorf("from = %q, want %q", from, step.from)
+		}
+		if step.to != BackendPlaintext {
+			assertNoCookies(t, s.Path)
+		}
+		assertCookies(t, s)
+	}
+	if _, err := os.Stat(encrypted); !os.IsNotExist(err) {
+		t.Errorf("encrypted file left behind after migrating away: %v", err)
+	}
+	if _, err := keyring.Get(keyringService, "a"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("keyring still has a after migrating away: %v", err)
+	}
+	if _, err := s.Migrate("vault"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestDefaultBackend(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(EnvSecretBackend, "")
+	if got, err := DefaultBackend(); err != nil || got != BackendKeyring {
+		t.Errorf("with keyring: %q, %v", got, err)
+	}
+	t.Setenv(EnvSecretBackend, BackendPlaintext)
+	if got, err := DefaultBackend(); err != nil || got != BackendPlaintext {
+		t.Errorf("explicit plaintext: %q, %v", got, err)
+	}
+	t.Setenv(EnvSecretBackend, "vault")
+	if _, err := DefaultBackend(); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
@@ -7,10 +7,34 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
 )
 
-func configDir() (string, error) {
+// EnvConfigDir relocates the directory holding accounts.json and
+// config.json.
+const EnvConfigDir = "SUBSTACK_CONFIG_DIR"
+
+// relocatedDir returns the config directory chosen by override (from the
+// --config-dir flag) or SUBSTACK_CONFIG_DIR, or "" when neither is set.
+func relocatedDir(override string) string {
+	if override != "" {
+		return override
+	}
+	return os.Getenv(EnvConfigDir)
+}
+
+// ConfigDir returns the directory holding accounts.json and config.json:
+// override, else SUBSTACK_CONFIG_DIR, else substack-cli under
+// XDG_CONFIG_HOME, else ~/.config/substack-cli.
+func ConfigDir(override string) (string, error) {
+	if dir := relocatedDir(override); dir != "" {
+		return dir, nil
+	}
+	// The XDG spec says relative paths are invalid and should be ignored.
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "substack-cli"), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -18,45 +42,98 @@ func configDir() (string, error) {
 	return filepath.Join(home, ".config", "substack-cli"), nil
 }
 
-func ConfigDir() (string, error) {
-	return configDir()
+// Store is the account store in one accounts.json, together with what its
+// secret backends need from the caller. Use one Store per process, so the
+// passphrase of the encrypted secrets file is asked for at most once.
+type Store struct {
+	// Path is the accounts.json file; secrets.enc lives next to it.
+	Path string
+	// Prompt asks for the encrypted secrets file's passphrase when
+	// SUBSTACK_PASSPHRASE is unset; confirm is set when a new file is created.
+	// When nil, the variable is required.
+	Prompt func(confirm bool) (string, error)
+	// KDFIterations is the PBKDF2-SHA256 work factor for newly written
+	// secrets files; 0 means defaultKDFIterations.
+	KDFIterations int
+	// KeyringService is the service the keyring backend files secrets under;
+	// empty means substack-cli.
+	KeyringService string
+	// Warn, when set, receives problems found on load that do not stop the
+	// command, such as cookies kept in plaintext without anyone choosing so.
+	Warn func(msg string)
+
+	passphrase string // cached after it was first read or prompted for
+	warned     bool   // Warn was called for the plaintext default
 }
 
-func storeFile() (string, error) {
-	dir, err := configDir()
+// DefaultStore returns the store in the config directory (see ConfigDir). A
+// relocated directory files its keyring items under a service of its own, so
+// an isolated config root cannot overwrite the user's real cookies.
+func DefaultStore(override string) (*Store, error) {
+	dir, err := ConfigDir(override)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return filepath.Join(dir, "accounts.json"), nil
+	s := &Store{Path: filepath.Join(dir, "accounts.json")}
+	if relocatedDir(override) != "" {
+		abs, absErr := filepath.Abs(dir)
+		if absErr != nil {
+			return nil, absErr
+		}
+		s.KeyringService = keyringServiceFor(abs)
+	}
+	return s, nil
 }
 
-func Load() (*model.AccountStore, error) {
-	path, err := storeFile()
+// Load reads the store, with each account's cookies filled in from the
+// store's secret backend.
+func (s *Store) Load() (*model.AccountStore, error) {
+	store, err := readStore(s.Path)
 	if err != nil {
 		return nil, err
 	}
-	data, readErr := os.ReadFile(path)
-	if readErr != nil {
-		if os.IsNotExist(readErr) {
-			return &model.AccountStore{}, nil
-		}
-		return nil, readErr
+	s.warnPlaintext(store)
+	if secretsErr := s.loadSecrets(store); secretsErr != nil {
+		return nil, secretsErr
 	}
-	var store model.AccountStore
-	if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
-		return nil, unmarshalErr
+	return store, nil
+}
+
+// warnPlaintext warns once when a store that predates secret backends still
+// keeps cookies in accounts.json. Choosing plaintext explicitly, with
+// 'auth migrate --to plaintext', silences it.
+func (s *Store) warnPlaintext(store *model.AccountStore) {
+	if s.Warn == nil || s.warned || store.SecretBackend != "" {
+		return
+	}
+	for _, a := range store.Accounts {
+		if secretsOf(a) != (Secrets{}) {
+			s.warned = true
+			s.Warn("cookies are stored in plaintext in accounts.json; run 'substack auth migrate --to keyring' " +
+				"(or --to file) to encrypt them, or --to plaintext to keep them there")
+			return
+		}
 	}
-	return &store, nil
 }
 
-func LoadFrom(path string) (*model.AccountStore, error) {
+// storeMigrations upgrade accounts.json from each schema version to the
+// next; the current version is their count. Version 1 only adds the field.
+var storeMigrations = []filestore.Migration{
+	func(map[string]any) error { return nil },
+}
+
+func readStore(path string) (*model.AccountStore, error) {
 	data, readErr := os.ReadFile(path)
 	if readErr != nil {
 		if os.IsNotExist(readErr) {
-			return &model.AccountStore{}, nil
+			return &model.AccountStore{Version: len(storeMigrations)}, nil
 		}
 		return nil, readErr
 	}
+	data, upgradeErr := filestore.Upgrade(data, storeMigrations)
+	if upgradeErr != nil {
+		return nil, fmt.Errorf("%s: %w", path, upgradeErr)
+	}
 	var store model.AccountStore
 	if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
 		return nil, unmarshalErr
@@ -64,23 +141,102 @@ func LoadFrom(path string) (*model.AccountStore, error) {
 	return &store, nil
 }
 
-func Save(store *model.AccountStore) error {
-	path, err := storeFile()
+// Save atomically replaces the store, handing cookies to the store's secret
+// backend unless it keeps them in the file. It does not lock; use Update for
+// read-modify-write cycles.
+func (s *Store) Save(store *model.AccountStore) error {
+	return s.save(store, nil)
+}
+
+// save is Save, writing to the backend only the secrets that differ from
+// loaded.
+func (s *Store) save(store *model.AccountStore, loaded map[string]Secrets) error {
+	store.Version = len(storeMigrations)
+	var previous []string
+	if old, readErr := readStore(s.Path); readErr == nil {
+		previous = accountNames(old.Accounts)
+	}
+	onDisk, err := s.saveSecrets(store, previous, loaded)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(onDisk, "", "  ")
+	if err != nil {
+		return err
+	}
+	return filestore.WriteAtomic(s.Path, data, 0600)
+}
+
+// Update loads the store, applies fn and saves the result while holding the
+// store's lock, so concurrent invocations cannot lose each other's changes.
+// Nothing is saved when fn fails, and only the cookies fn changed are written
+// to the secret backend.
+func (s *Store) Update(fn func(*model.AccountStore) error) error {
+	unlock, err := filestore.Lock(s.Path)
 	if err != nil {
 		return err
 	}
-	return SaveTo(store, path)
+	defer unlock()
+	store, err := s.Load()
+	if err != nil {
+		return err
+	}
+	loaded := make(map[string]Secrets, len(store.Accounts))
+	for _, a := range store.Accounts {
+		loaded[a.Name] = secretsOf(a)
+	}
+	if fnErr := fn(store); fnErr != nil {
+		return fnErr
+	}
+	return s.save(store, loaded)
 }
 
-func SaveTo(store *model.AccountStore, path string) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+// SetActive makes the named account the active one. Only accounts.json is
+// rewritten; the secret backend is never touched, so switching accounts does
+// not ask for a passphrase.
+func (s *Store) SetActive(name string) error {
+	unlock, err := filestore.Lock(s.Path)
+	if err != nil {
 		return err
 	}
+	defer unlock()
+	store, err := readStore(s.Path)
+	if err != nil {
+		return err
+	}
+	if switchErr := SwitchAccount(store, name); switchErr != nil {
+		return switchErr
+	}
+	store.Version = len(storeMigrations)
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0600)
+	return filestore.WriteAtomic(s.Path, data, 0600)
+}
+
+// Names returns the names of the stored accounts. Unlike Load it never reads
+// secrets, so it cannot prompt.
+func (s *Store) Names() ([]string, error) {
+	store, err := readStore(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return accountNames(store.Accounts), nil
+}
+
+// UpdateCookies replaces the stored cookies of acct's account, e.g. after
+// Substack rotated them, leaving everything else in the store as it is.
+func (s *Store) UpdateCookies(acct model.Account) error {
+	return s.Update(func(store *model.AccountStore) error {
+		for i := range store.Accounts {
+			if store.Accounts[i].Name == acct.Name {
+				secretsOf(acct).apply(&store.Accounts[i])
+				return nil
+			}
+		}
+		return fmt.Errorf("account %q not found", acct.Name)
+	})
 }
 
 func AddAccount(store *model.AccountStore, acct model.Account) {
@@ -121,6 +277,24 @@ func GetActive(store *model.AccountStore) (*model.Account, error) {
 	return nil, errors.New("no active account; run 'substack auth login'")
 }
 
+// GetAccount returns the named account without changing which one is active.
+func GetAccount(store *model.AccountStore, name string) (*model.Account, error) {
+	for _, a := range store.Accounts {
+		if a.Name == name {
+			return &a, nil
+		}
+	}
+	return nil, fmt.Errorf("account %q not found", name)
+}
+
+// Select returns the named account, or the active one when name is empty.
+func Select(store *model.AccountStore, name string) (*model.Account, error) {
+	if name == "" {
+		return GetActive(store)
+	}
+	return GetAccount(store, name)
+}
+
 func SwitchAccount(store *model.AccountStore, name string) error {
 	for _, a := range store.Accounts {
 		if a.Name == name {
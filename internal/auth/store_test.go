@@ -1,16 +1,21 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/aaronsrivastava/substack-cli/internal/filestore"
 	"github.com/aaronsrivastava/substack-cli/internal/model"
 )
 
-func tmpStore(t *testing.T) string {
+func tmpStore(t *testing.T) *Store {
 	t.Helper()
-	return filepath.Join(t.TempDir(), "accounts.json")
+	return &Store{Path: filepath.Join(t.TempDir(), "accounts.json")}
 }
 
 func TestAddAndGetActive(t *testing.T) {
@@ -31,15 +36,15 @@ func TestAddAndGetActive(t *testing.T) {
 }
 
 func TestSaveAndLoad(t *testing.T) {
-	path := tmpStore(t)
+	s := tmpStore(t)
 	store := &model.AccountStore{}
 	AddAccount(store, model.Account{Name: "a", PublicationURL: "https://a.substack.com"})
 
-	if err := SaveTo(store, path); err != nil {
+	if err := s.Save(store); err != nil {
 		t.Fatal(err)
 	}
 
-	loaded, err := LoadFrom(path)
+	loaded, err := s.Load()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -85,9 +90,36 @@ func TestSwitchAccount(t *testing.T) {
 	}
 }
 
+func TestSelectAccount(t *testing.T) {
+	store := &model.AccountStore{}
+	AddAccount(store, model.Account{Name: "a"})
+	AddAccount(store, model.Account{Name: "b"})
+
+	got, err := Select(store, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "b" {
+		t.Errorf("name = %q, want b", got.Name)
+	}
+	if store.Active != "a" {
+		t.Errorf("active = %q, want a (unchanged)", store.Active)
+	}
+	got, err = Select(store, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "a" {
+		t.Errorf("name = %q, want active account a", got.Name)
+	}
+	if _, err := Select(store, "nope"); err == nil {
+		t.Error("expected error for unknown account")
+	}
+}
+
 func TestLoadNonexistent(t *testing.T) {
-	path := filepath.Join(t.TempDir(), "nope.json")
-	store, err := LoadFrom(path)
+	s := &Store{Path: filepath.Join(t.TempDir(), "nope.json")}
+	store, err := s.Load()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -97,13 +129,13 @@ func TestLoadNonexistent(t *testing.T) {
 }
 
 func TestFilePermissions(t *testing.T) {
-	path := tmpStore(t)
+	s := tmpStore(t)
 	store := &model.AccountStore{}
 	AddAccount(store, model.Account{Name: "sec"})
-	if err := SaveTo(store, path); err != nil {
+	if err := s.Save(store); err != nil {
 		t.Fatal(err)
 	}
-	info, err := os.Stat(path)
+	info, err := os.Stat(s.Path)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -111,3 +143,155 @@ func TestFilePermissions(t *testing.T) {
 		t.Errorf("permissions = %o, want 0600", perm)
 	}
 }
+
+func TestUpdateCookies(t *testing.T) {
+	s := tmpStore(t)
+	store := &model.AccountStore{}
+	AddAccount(store, model.Account{Name: "a", UserID: "1", SubstackSID: "old"})
+	AddAccount(store, model.Account{Name: "b", SubstackSID: "other"})
+	if err := s.Save(store); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateCookies(model.Account{Name: "a", SubstackSID: "new", SubstackLLI: "lli"}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := GetAccount(loaded, "a")
+	if a.SubstackSID != "new" || a.SubstackLLI != "lli" || a.UserID != "1" {
+		t.Errorf("a = %+v, want new cookies and the old user ID", a)
+	}
+	if b, _ := GetAccount(loaded, "b"); b.SubstackSID != "other" {
+		t.Errorf("b = %+v, want unchanged", b)
+	}
+	if err := s.UpdateCookies(model.Account{Name: "gone"}); err == nil {
+		t.Error("expected error for unknown account")
+	}
+}
+
+func TestStoreVersion(t *testing.T) {
+	s := tmpStore(t)
+	legacy := `{"active": "a", "accounts": [{"name": "a", "publication_url": "https://a.substack.com", "sid": "x"}]}`
+	if err := os.WriteFile(s.Path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Version != len(storeMigrations) || store.Active != "a" || store.Accounts[0].SID != "x" {
+		t.Errorf("upgraded store = %+v", store)
+	}
+	if saveErr := s.Save(store); saveErr != nil {
+		t.Fatal(saveErr)
+	}
+	data, _ := os.ReadFile(s.Path)
+	if !strings.Contains(string(data), `"version": 1`) {
+		t.Errorf("saved store has no version:\n%s", data)
+	}
+
+	if writeErr := os.WriteFile(s.Path, []byte(`{"version": 99}`), 0600); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if _, err := s.Load(); !errors.Is(err, filestore.ErrNewerVersion) {
+		t.Errorf("err = %v, want ErrNewerVersion", err)
+	}
+}
+
+// TestConcurrentUpdate adds accounts from several goroutines at once and
+// checks that none is lost.
+func TestConcurrentUpdate(t *testing.T) {
+	s := tmpStore(t)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			err := s.Update(func(store *model.AccountStore) error {
+				AddAccount(store, model.Account{Name: fmt.Sprint("acct", i), PublicationURL: "https://a.substack.com"})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	store, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Accounts) != 8 {
+		t.Errorf("accounts = %d, want 8", len(store.Accounts))
+	}
+}
+
+func TestUpdateErrorSavesNothing(t *testing.T) {
+	s := tmpStore(t)
+	err := s.Update(func(store *model.AccountStore) error {
+		AddAccount(store, model.Account{Name: "a"})
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if _, statErr := os.Stat(s.Path); !os.IsNotExist(statErr) {
+		t.Errorf("store was written despite the error: %v", statErr)
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(EnvConfigDir, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	check := func(want string) {
+		t.Helper()
+		if got, err := ConfigDir(""); err != nil || got != want {
+			t.Errorf("ConfigDir() = %q, %v; want %q", got, err, want)
+		}
+	}
+	check(filepath.Join(home, ".config", "substack-cli"))
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+	check(filepath.Join(home, ".config", "substack-cli"))
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	check(filepath.Join(xdg, "substack-cli"))
+	t.Setenv(EnvConfigDir, filepath.Join(home, "env"))
+	check(filepath.Join(home, "env"))
+	flag := filepath.Join(home, "flag")
+	if got, err := ConfigDir(flag); err != nil || got != flag {
+		t.Errorf("ConfigDir(%q) = %q, %v", flag, got, err)
+	}
+}
+
+func TestDefaultStoreKeyringService(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(EnvConfigDir, "")
+
+	s, err := DefaultStore("")
+	if err != nil || s.KeyringService != "" {
+		t.Fatalf("default dir: service %q, %v; want the shared one", s.KeyringService, err)
+	}
+	services := map[string]bool{}
+	for _, dir := range []string{filepath.Join(home, "a"), filepath.Join(home, "b")} {
+		s, err = DefaultStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.KeyringService == "" || services[s.KeyringService] {
+			t.Errorf("%s: service %q is not its own", dir, s.KeyringService)
+		}
+		services[s.KeyringService] = true
+	}
+	t.Setenv(EnvConfigDir, filepath.Join(home, "a"))
+	if s, err = DefaultStore(""); err != nil || !services[s.KeyringService] {
+		t.Errorf("%s: service %q, %v; want the same as --config-dir", EnvConfigDir, s.KeyringService, err)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// ValidateAccount checks that an account has a usable name, an absolute
+// http(s) publication URL and at least one session cookie. Cookie values must
+// not contain whitespace or semicolons, which usually means a whole Cookie
+// header was pasted instead of one value.
+func ValidateAccount(a model.Account) error {
+	if a.Name == "" {
+		return errors.New("account name is required")
+	}
+	if strings.ContainsAny(a.Name, " \t/\\") {
+		return fmt.Errorf("account name %q must not contain spaces or slashes", a.Name)
+	}
+	if err := validatePublicationURL(a.PublicationURL); err != nil {
+		return err
+	}
+	if a.SID == "" && a.SubstackSID == "" {
+		return errors.New("a connect.sid or substack.sid cookie is required")
+	}
+	for label, v := range map[string]string{
+		"connect.sid":  a.SID,
+		"substack.sid": a.SubstackSID,
+		"substack.lli": a.SubstackLLI,
+	} {
+		if strings.ContainsAny(v, " \t\r\n;") {
+			return fmt.Errorf("%s cookie must be a single value without spaces or semicolons", label)
+		}
+	}
+	return nil
+}
+
+func validatePublicationURL(raw string) error {
+	if raw == "" {
+		return errors.New("publication URL is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("publication URL %q must be an absolute http(s) URL such as https://you.substack.com", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("publication URL %q must not have a query or fragment", raw)
+	}
+	return nil
+}
+
+// NormalizeAccount trims surrounding whitespace from every field and the
+// trailing slash from the publication URL.
+func NormalizeAccount(a *model.Account) {
+	for _, field := range []*string{&a.Name, &a.PublicationURL, &a.UserID, &a.SID, &a.SubstackSID, &a.SubstackLLI} {
+		*field = strings.TrimSpace(*field)
+	}
+	a.PublicationURL = strings.TrimRight(a.PublicationURL, "/")
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+func TestValidateAccount(t *testing.T) {
+	valid := model.Account{Name: "blog", PublicationURL: "https://you.substack.com", SubstackSID: "s%3Aabc.def"}
+	if err := ValidateAccount(valid); err != nil {
+		t.Fatalf("valid account: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*model.Account)
+	}{
+		{"empty name", func(a *model.Account) { a.Name = "" }},
+		{"name with space", func(a *model.Account) { a.Name = "my blog" }},
+		{"name with slash", func(a *model.Account) { a.Name = "a/b" }},
+		{"empty url", func(a *model.Account) { a.PublicationURL = "" }},
+		{"bare host", func(a *model.Account) { a.PublicationURL = "you.substack.com" }},
+		{"wrong scheme", func(a *model.Account) { a.PublicationURL = "ftp://you.substack.com" }},
+		{"url with query", func(a *model.Account) { a.PublicationURL = "https://you.substack.com/?x=1" }},
+		{"no session cookie", func(a *model.Account) { a.SubstackSID = "" }},
+		{"pasted cookie header", func(a *model.Account) { a.SubstackSID = "substack.sid=abc; substack.lli=1" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acct := valid
+			tt.modify(&acct)
+			if err := ValidateAccount(acct); err == nil {
+				t.Errorf("expected error for %+v", acct)
+			}
+		})
+	}
+}
+
+func TestNormalizeAccount(t *testing.T) {
+	acct := model.Account{Name: " blog ", PublicationURL: "https://you.substack.com/ ", SID: "sid\n"}
+	NormalizeAccount(&acct)
+	if acct.Name != "blog" || acct.PublicationURL != "https://you.substack.com" || acct.SID != "sid" {
+		t.Errorf("acct = %+v", acct)
+	}
+}
@@ -0,0 +1,221 @@
+// Package browser reads Substack session cookies out of a local browser
+// profile, so accounts can be added without copying them from DevTools.
+package browser
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// Supported browsers. Chrome reads the same database format as Chromium
+// from its own profile directory.
+const (
+	Firefox  = "firefox"
+	Chromium = "chromium"
+	Chrome   = "chrome"
+)
+
+// Browsers lists the supported browser names.
+var Browsers = []string{Firefox, Chromium, Chrome}
+
+// CookieNames are the Substack cookies an account needs.
+var CookieNames = []string{"connect.sid", "substack.sid", "substack.lli"}
+
+// Domain is the cookie domain sessions are read from.
+const Domain = "substack.com"
+
+// ErrNoSession means the profile has none of the Substack session cookies.
+var ErrNoSession = errors.New("no Substack session cookies found; sign in to substack.com in that browser first")
+
+// cookie is one row of a browser cookie database.
+type cookie struct {
+	name, value, host string
+	expires, used     time.Time
+}
+
+// Cookies reads the Substack session cookies for the publication at host
+// from a browser profile. The profile is the profile directory or the cookie
+// database itself; when empty, the browser's default profile location is
+// searched. Host may be empty when the publication is not known yet. The
+// result maps cookie name to value and holds only the cookies found.
+func Cookies(name, profile, host string) (map[string]string, error) {
+	if !slices.Contains(Browsers, name) {
+		return nil, fmt.Errorf("unsupported browser %q (use %s)", name, strings.Join(Browsers, ", "))
+	}
+	path, err := cookieDB(name, profile)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.ToLower(host)
+	var rows []cookie
+	if name == Firefox {
+		rows, err = readFirefox(path, host)
+	} else {
+		rows, err = readChromium(path, host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	found := pick(rows, host, time.Now())
+	if len(found) == 0 {
+		return nil, ErrNoSession
+	}
+	return found, nil
+}
+
+// Host ranks for pick, best first.
+const (
+	rankExact = iota
+	rankDomain
+	rankSubdomain
+	rankOther
+)
+
+// hostRank ranks a cookie's host for the publication at target: its own
+// host first, then substack.com itself, then other publications' subdomains,
+// which belong to other sessions when several are signed in.
+func hostRank(cookieHost, target string) int {
+	host := strings.TrimPrefix(cookieHost, ".")
+	switch {
+	case target != "" && strings.EqualFold(host, target):
+		return rankExact
+	case host == Domain:
+		return rankDomain
+	case strings.HasSuffix(host, "."+Domain):
+		return rankSubdomain
+	}
+	return rankOther
+}
+
+// pick keeps, for each wanted cookie, the unexpired value from the best
+// ranked host (see hostRank), and among those the most recently used one.
+func pick(rows []cookie, host string, now time.Time) map[string]string {
+	found := map[string]string{}
+	best := map[string]cookie{}
+	ranks := map[string]int{}
+	for _, c := range rows {
+		rank := hostRank(c.host, host)
+		if rank == rankOther {
+			continue
+		}
+		if !slices.Contains(CookieNames, c.name) || c.value == "" {
+			continue
+		}
+		if !c.expires.IsZero() && c.expires.Before(now) {
+			continue
+		}
+		if prev, ok := best[c.name]; ok {
+			if rank > ranks[c.name] || rank == ranks[c.name] && !c.used.After(prev.used) {
+				continue
+			}
+		}
+		best[c.name], ranks[c.name] = c, rank
+		found[c.name] = c.value
+	}
+	return found
+}
+
+// cookieDB resolves the cookie database for a browser profile.
+func cookieDB(name, profile string) (string, error) {
+	candidates := []string{"cookies.sqlite"}
+	if name != Firefox {
+		candidates = []string{"Cookies", filepath.Join("Network", "Cookies")}
+	}
+	dirs := []string{profile}
+	if profile == "" {
+		var err error
+		if dirs, err = defaultProfiles(name); err != nil {
+			return "", err
+		}
+	} else if info, err := os.Stat(profile); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		return profile, nil
+	}
+	for _, dir := range dirs {
+		for _, c := range candidates {
+			path := filepath.Join(dir, c)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	if profile == "" {
+		return "", fmt.Errorf("no %s profile found; pass --profile", name)
+	}
+	return "", fmt.Errorf("no %s cookie database in %s", name, profile)
+}
+
+// defaultProfiles lists the usual profile directories on Linux, most likely
+// first.
+func defaultProfiles(name string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case Firefox:
+		var dirs []string
+		for _, pattern := range []string{"*.default-release", "*.default*", "*"} {
+			matches, _ := filepath.Glob(filepath.Join(home, ".mozilla", "firefox", pattern))
+			dirs = append(dirs, matches...)
+		}
+		return dirs, nil
+	case Chrome:
+		return []string{filepath.Join(home, ".config", "google-chrome", "Default")}, nil
+	}
+	return []string{filepath.Join(home, ".config", "chromium", "Default")}, nil
+}
+
+// openCopy opens a private copy of a cookie database. Running browsers keep
+// theirs locked and write recent changes to a -wal file beside it, so both
+// are copied before reading.
+func openCopy(path string) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "substack-cookies-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	dst := filepath.Join(dir, "cookies.db")
+	for _, suffix := range []string{"", "-wal"} {
+		if copyErr := copyFile(path+suffix, dst+suffix); copyErr != nil {
+			if suffix != "" && errors.Is(copyErr, os.ErrNotExist) {
+				continue
+			}
+			cleanup()
+			return nil, nil, copyErr
+		}
+	}
+	db, err := sql.Open("sqlite", "file:"+dst+"?mode=ro")
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() { _ = db.Close(); cleanup() }, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, copyErr := io.Copy(out, in); copyErr != nil {
+		_ = out.Close()
+		return copyErr
+	}
+	return out.Close()
+}
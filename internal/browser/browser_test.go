@@ -0,0 +1,249 @@
+package browser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureDB creates a SQLite database at path and runs the statements.
+func fixtureDB(t *testing.T, path string, stmts ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	for _, stmt := range stmts {
+		if _, execErr := db.Exec(stmt); execErr != nil {
+			t.Fatalf("%s: %v", stmt, execErr)
+		}
+	}
+}
+
+func firefoxProfile(t *testing.T, rows ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	stmts := []string{`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT,
+		path TEXT, expiry INTEGER, lastAccessed INTEGER)`}
+	for _, r := range rows {
+		stmts = append(stmts, "INSERT INTO moz_cookies (name, value, host, path, expiry, lastAccessed) VALUES "+r)
+	}
+	fixtureDB(t, filepath.Join(dir, "cookies.sqlite"), stmts...)
+	return dir
+}
+
+func TestFirefoxCookies(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+	exp := func(at time.Time) string { return itoa(at.Unix()) }
+	used := func(at time.Time) string { return itoa(at.UnixMicro()) }
+	profile := firefoxProfile(t,
+		`('substack.sid', 'old-sid', '.substack.com', '/', `+exp(future)+`, `+used(past)+`)`,
+		`('substack.sid', 'new-sid', 'you.substack.com', '/', `+exp(future)+`, `+used(time.Now())+`)`,
+		`('substack.lli', 'lli', '.substack.com', '/', `+itoa(future.UnixMilli())+`, `+used(past)+`)`,
+		`('connect.sid', 'expired', '.substack.com', '/', `+exp(past)+`, `+used(past)+`)`,
+		`('substack.sid', 'elsewhere', '.notsubstack.com', '/', `+exp(future)+`, `+used(future)+`)`,
+		`('_ga', 'tracking', '.substack.com', '/', `+exp(future)+`, `+used(past)+`)`,
+	)
+
+	got, err := Cookies(Firefox, profile, "you.substack.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["substack.sid"] != "new-sid" || got["substack.lli"] != "lli" {
+		t.Errorf("cookies = %v, want the publication's substack.sid and substack.lli", got)
+	}
+
+	// The database file itself works as well as the profile directory.
+	if _, err := Cookies(Firefox, filepath.Join(profile, "cookies.sqlite"), ""); err != nil {
+		t.Errorf("database path: %v", err)
+	}
+}
+
+func TestPickPrefersPublicationHost(t *testing.T) {
+	now := time.Now()
+	rows := []cookie{
+		{name: "substack.sid", value: "mine", host: "you.substack.com", used: now.Add(-2 * time.Hour)},
+		{name: "substack.sid", value: "shared", host: ".substack.com", used: now.Add(-time.Hour)},
+		{name: "substack.sid", value: "theirs", host: "other.substack.com", used: now},
+		{name: "substack.lli", value: "lli-theirs", host: "other.substack.com", used: now},
+		{name: "connect.sid", value: "custom", host: "blog.example.com", used: now},
+	}
+	for _, tc := range []struct {
+		host string
+		want map[string]string
+	}{
+		{"you.substack.com", map[string]string{"substack.sid": "mine", "substack.lli": "lli-theirs"}},
+		{"", map[string]string{"substack.sid": "shared", "substack.lli": "lli-theirs"}},
+		{"new.substack.com", map[string]string{"substack.sid": "shared", "substack.lli": "lli-theirs"}},
+		{"blog.example.com", map[string]string{
+			"substack.sid": "shared", "substack.lli": "lli-theirs", "connect.sid": "custom",
+		}},
+	} {
+		if got := pick(rows, tc.host, now); !maps.Equal(got, tc.want) {
+			t.Errorf("host %q: cookies = %v, want %v", tc.host, got, tc.want)
+		}
+	}
+}
+
+func TestCustomDomainCookies(t *testing.T) {
+	future := itoa(time.Now().Add(24 * time.Hour).Unix())
+	used := func(at time.Time) string { return itoa(at.UnixMicro()) }
+	profile := firefoxProfile(t,
+		`('connect.sid', 'custom-sid', 'blog.example.com', '/', `+future+`, `+used(time.Now().Add(-time.Hour))+`)`,
+		`('substack.lli', 'custom-lli', '.blog.example.com', '/', `+future+`, `+used(time.Now().Add(-time.Hour))+`)`,
+		`('connect.sid', 'shared-sid', '.substack.com', '/', `+future+`, `+used(time.Now())+`)`,
+		`('connect.sid', 'unrelated', 'other.example.com', '/', `+future+`, `+used(time.Now())+`)`,
+	)
+	got, err := Cookies(Firefox, profile, "Blog.Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["connect.sid"] != "custom-sid" || got["substack.lli"] != "custom-lli" {
+		t.Errorf("cookies = %v, want the custom domain's", got)
+	}
+	got, err = Cookies(Firefox, profile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["connect.sid"] != "shared-sid" {
+		t.Errorf("without a host: cookies = %v, want substack.com's only", got)
+	}
+}
+
+func TestNoSession(t *testing.T) {
+	profile := firefoxProfile(t, `('_ga', 'x', '.substack.com', '/', 0, 0)`)
+	if _, err := Cookies(Firefox, profile, ""); !errors.Is(err, ErrNoSession) {
+		t.Errorf("err = %v, want ErrNoSession", err)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	if _, err := Cookies("netscape", t.TempDir(), ""); err == nil {
+		t.Error("expected error for unsupported browser")
+	}
+	_, err := Cookies(Firefox, t.TempDir(), "")
+	if err == nil || !strings.Contains(err.Error(), "no firefox cookie database") {
+		t.Errorf("err = %v, want missing database", err)
+	}
+}
+
+// encryptV10 encrypts a value the way Chromium on Linux does without a
+// keyring, optionally prefixing the host hash of newer databases.
+func encryptV10(t *testing.T, value, host string, hashed bool) string {
+	t.Helper()
+	plain := []byte(value)
+	if hashed {
+		sum := sha256.Sum256([]byte(host))
+		plain = append(sum[:], plain...)
+	}
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(pad)}, pad)...)
+	key, err := pbkdf2.Key(sha1.New, "peanuts", []byte("saltysalt"), 1, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(out, plain)
+	return "X'" + hex.EncodeToString(append([]byte("v10"), out...)) + "'"
+}
+
+func chromiumProfile(t *testing.T, dbPath string, version int, rows ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	stmts := []string{
+		`CREATE TABLE meta (key TEXT PRIMARY KEY, value TEXT)`,
+		`INSERT INTO meta VALUES ('version', '` + itoa(int64(version)) + `')`,
+		`CREATE TABLE cookies (creation_utc INTEGER, host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB,
+			path TEXT, expires_utc INTEGER, last_access_utc INTEGER)`,
+	}
+	for _, r := range rows {
+		stmts = append(stmts, `INSERT INTO cookies (host_key, name, value, encrypted_value, path, expires_utc,
+			last_access_utc) VALUES `+r)
+	}
+	fixtureDB(t, filepath.Join(dir, dbPath), stmts...)
+	return dir
+}
+
+func chromiumStamp(at time.Time) string {
+	return itoa(at.UnixMicro() + chromiumEpochOffset)
+}
+
+func TestChromiumCookies(t *testing.T) {
+	future, now := chromiumStamp(time.Now().Add(24*time.Hour)), chromiumStamp(time.Now())
+	for _, tt := range []struct {
+		name    string
+		dbPath  string
+		version int
+	}{
+		{"legacy layout", "Cookies", 18},
+		{"host hash", filepath.Join("Network", "Cookies"), 24},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hashed := tt.version >= 24
+			profile := chromiumProfile(t, tt.dbPath, tt.version,
+				`('.substack.com', 'substack.sid', '', `+encryptV10(t, "s%3Achrome", ".substack.com", hashed)+
+					`, '/', `+future+`, `+now+`)`,
+				`('.substack.com', 'connect.sid', 'plain-sid', X'', '/', 0, `+now+`)`,
+				`('.example.com', 'substack.lli', '', `+encryptV10(t, "other", ".example.com", hashed)+
+					`, '/', `+future+`, `+now+`)`,
+			)
+			got, err := Cookies(Chromium, profile, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || got["substack.sid"] != "s%3Achrome" || got["connect.sid"] != "plain-sid" {
+				t.Errorf("cookies = %v", got)
+			}
+		})
+	}
+}
+
+func TestChromiumCustomDomain(t *testing.T) {
+	now := chromiumStamp(time.Now())
+	profile := chromiumProfile(t, "Cookies", 18,
+		`('.blog.example.com', 'connect.sid', 'custom-sid', X'', '/', 0, `+now+`)`,
+		`('.other.example.com', 'substack.lli', 'unrelated', X'', '/', 0, `+now+`)`,
+	)
+	got, err := Cookies(Chromium, profile, "blog.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["connect.sid"] != "custom-sid" {
+		t.Errorf("cookies = %v, want the custom domain's connect.sid", got)
+	}
+}
+
+func TestChromiumKeyringEncrypted(t *testing.T) {
+	profile := chromiumProfile(t, "Cookies", 24,
+		`('.substack.com', 'substack.sid', '', X'7631310102030405060708090a0b0c0d0e0f10', '/', 0, 0)`)
+	_, err := Cookies(Chromium, profile, "")
+	if err == nil || !strings.Contains(err.Error(), "v11") {
+		t.Errorf("err = %v, want v11 error", err)
+	}
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
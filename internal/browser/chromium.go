@@ -0,0 +1,127 @@
+package browser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Chromium on Linux without a desktop keyring encrypts cookie values with a
+// fixed password ("v10" values). Values encrypted with a key from the
+// keyring ("v11") cannot be read this way.
+const (
+	chromiumPassword   = "peanuts"
+	chromiumSalt       = "saltysalt"
+	chromiumIterations = 1
+	chromiumKeySize    = 16
+	// chromiumDomainHashVersion is the meta version from which decrypted
+	// values start with the SHA-256 of the cookie's host.
+	chromiumDomainHashVersion = 24
+)
+
+// chromiumEpochOffset is the number of microseconds from 1601-01-01, where
+// Chromium's timestamps count from, to the Unix epoch.
+const chromiumEpochOffset = 11_644_473_600_000_000
+
+func chromiumTime(v int64) time.Time {
+	return time.UnixMicro(v - chromiumEpochOffset)
+}
+
+// readChromium reads the cookies table of a Chromium Cookies database: the
+// cookies of substack.com and its subdomains, and those of host.
+func readChromium(path, host string) ([]cookie, error) {
+	db, done, err := openCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	version, err := chromiumVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT name, value, encrypted_value, host_key, expires_utc, last_access_utc
+		FROM cookies WHERE host_key LIKE ? OR host_key = ? OR host_key = ?`, "%"+Domain, host, "."+host)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var cookies []cookie
+	for rows.Next() {
+		var c cookie
+		var encrypted []byte
+		var expires, lastAccess int64
+		if scanErr := rows.Scan(&c.name, &c.value, &encrypted, &c.host, &expires, &lastAccess); scanErr != nil {
+			return nil, scanErr
+		}
+		if c.value == "" && len(encrypted) > 0 && slices.Contains(CookieNames, c.name) {
+			if c.value, err = decryptChromium(encrypted, version); err != nil {
+				return nil, fmt.Errorf("cookie %s: %w", c.name, err)
+			}
+		}
+		if expires > 0 {
+			c.expires = chromiumTime(expires)
+		}
+		c.used = chromiumTime(lastAccess)
+		cookies = append(cookies, c)
+	}
+	return cookies, rows.Err()
+}
+
+func chromiumVersion(db *sql.DB) (int, error) {
+	var raw string
+	err := db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(raw)
+}
+
+// decryptChromium decrypts a "v10" cookie value: AES-128-CBC with a key
+// derived from the fixed password and an IV of sixteen spaces.
+func decryptChromium(encrypted []byte, version int) (string, error) {
+	switch {
+	case bytes.HasPrefix(encrypted, []byte("v11")):
+		return "", errors.New("encrypted with the desktop keyring (v11), which is not supported; " +
+			"copy the cookies from DevTools and use 'substack auth login' instead")
+	case !bytes.HasPrefix(encrypted, []byte("v10")):
+		return "", fmt.Errorf("unknown encryption version %q", encrypted[:min(3, len(encrypted))])
+	}
+	data := encrypted[3:]
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", errors.New("malformed encrypted value")
+	}
+	key, err := pbkdf2.Key(sha1.New, chromiumPassword, []byte(chromiumSalt), chromiumIterations, chromiumKeySize)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(plain, data)
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || pad > len(plain) {
+		return "", errors.New("bad padding; the value was not encrypted with the default key")
+	}
+	plain = plain[:len(plain)-pad]
+	if version >= chromiumDomainHashVersion {
+		if len(plain) < sha256.Size {
+			return "", errors.New("decrypted value too short")
+		}
+		plain = plain[sha256.Size:]
+	}
+	return string(plain), nil
+}
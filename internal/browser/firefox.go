@@ -0,0 +1,43 @@
+package browser
+
+import "time"
+
+// readFirefox reads the moz_cookies table of a Firefox cookies.sqlite: the
+// cookies of substack.com and its subdomains, and those of host.
+func readFirefox(path, host string) ([]cookie, error) {
+	db, done, err := openCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	rows, err := db.Query(`SELECT name, value, host, expiry, lastAccessed FROM moz_cookies
+		WHERE host LIKE ? OR host = ? OR host = ?`, "%"+Domain, host, "."+host)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var cookies []cookie
+	for rows.Next() {
+		var c cookie
+		var expiry, lastAccessed int64
+		if scanErr := rows.Scan(&c.name, &c.value, &c.host, &expiry, &lastAccessed); scanErr != nil {
+			return nil, scanErr
+		}
+		c.expires = firefoxExpiry(expiry)
+		c.used = time.UnixMicro(lastAccessed)
+		cookies = append(cookies, c)
+	}
+	return cookies, rows.Err()
+}
+
+// firefoxExpiry converts moz_cookies.expiry, which is in seconds in older
+// profiles and milliseconds in newer ones.
+func firefoxExpiry(v int64) time.Time {
+	if v <= 0 {
+		return time.Time{}
+	}
+	if v > 1e11 {
+		return time.UnixMilli(v)
+	}
+	return time.Unix(v, 0)
+}
@@ -0,0 +1,118 @@
+// Package filestore provides safe updates of the CLI's JSON state files:
+// advisory locking across processes, atomic replacement, and schema
+// version upgrades.
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockTimeout bounds how long Lock waits for another process to finish.
+var LockTimeout = 10 * time.Second
+
+// lockPollInterval is how often Lock retries a held lock.
+const lockPollInterval = 25 * time.Millisecond
+
+// Lock takes an exclusive advisory lock guarding path, held on a separate
+// path+".lock" file so the data file itself can be replaced atomically. Call
+// the returned function to release it. Locks are per open file, so a process
+// must not lock the same path twice.
+func Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		locked, lockErr := tryLock(f)
+		if lockErr != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("locking %s: %w", path, lockErr)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for another substack process to release %s",
+				LockTimeout, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+	return func() {
+		_ = unlock(f)
+		_ = f.Close()
+	}, nil
+}
+
+// WriteAtomic writes data to a temporary file next to path and renames it
+// into place, so readers see either the old or the new content, never a mix.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, writeErr := tmp.Write(data); writeErr != nil {
+		_ = tmp.Close()
+		return writeErr
+	}
+	if syncErr := tmp.Sync(); syncErr != nil {
+		_ = tmp.Close()
+		return syncErr
+	}
+	if closeErr := tmp.Close(); closeErr != nil {
+		return closeErr
+	}
+	if chmodErr := os.Chmod(tmp.Name(), perm); chmodErr != nil {
+		return chmodErr
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Migration upgrades a decoded document from one schema version to the next.
+type Migration func(doc map[string]any) error
+
+// ErrNewerVersion means a file was written by a newer release with a schema
+// this one does not know.
+var ErrNewerVersion = errors.New("file was written by a newer version of substack-cli; upgrade to use it")
+
+// Upgrade brings a JSON document to the latest schema version, which is
+// len(migrations). The document's "version" field says where it starts;
+// files written before versioning have none and start at 0. migrations[i]
+// upgrades version i to i+1.
+func Upgrade(data []byte, migrations []Migration) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	version := 0
+	if v, ok := doc["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("schema version %d: %w", version, ErrNewerVersion)
+	}
+	if version == len(migrations) {
+		return data, nil
+	}
+	for i := version; i < len(migrations); i++ {
+		if err := migrations[i](doc); err != nil {
+			return nil, fmt.Errorf("upgrading schema version %d to %d: %w", i, i+1, err)
+		}
+	}
+	doc["version"] = len(migrations)
+	return json.Marshal(doc)
+}
@@ -0,0 +1,128 @@
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "state.json")
+	if err := WriteAtomic(path, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteAtomic(path, []byte(`{"a":2}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":2}` {
+		t.Errorf("content = %s", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("leftover files: %v", entries)
+	}
+}
+
+// TestLockSerializes runs read-modify-write cycles from several goroutines,
+// each through its own lock file handle as separate processes would, and
+// checks that no increment is lost.
+func TestLockSerializes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+	if err := WriteAtomic(path, []byte("0"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const workers, rounds = 4, 10
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for range rounds {
+				unlock, err := Lock(path)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				data, _ := os.ReadFile(path)
+				var n int
+				_ = json.Unmarshal(data, &n)
+				out, _ := json.Marshal(n + 1)
+				if writeErr := WriteAtomic(path, out, 0600); writeErr != nil {
+					t.Error(writeErr)
+				}
+				unlock()
+			}
+		})
+	}
+	wg.Wait()
+	data, _ := os.ReadFile(path)
+	if string(data) != "40" {
+		t.Errorf("counter = %s, want 40", data)
+	}
+}
+
+func TestLockTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	old := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { LockTimeout = old })
+	if _, err := Lock(path); err == nil {
+		t.Error("expected timeout while the lock is held")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	migrations := []Migration{
+		func(doc map[string]any) error { doc["b"] = doc["a"]; delete(doc, "a"); return nil },
+		func(doc map[string]any) error { doc["c"] = true; return nil },
+	}
+
+	out, err := Upgrade([]byte(`{"a": "x"}`), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	_ = json.Unmarshal(out, &doc)
+	if doc["b"] != "x" || doc["c"] != true || doc["version"] != float64(2) || doc["a"] != nil {
+		t.Errorf("unversioned: %v", doc)
+	}
+
+	out, err = Upgrade([]byte(`{"version": 1, "b": "y"}`), migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = nil
+	_ = json.Unmarshal(out, &doc)
+	if doc["b"] != "y" || doc["c"] != true || doc["version"] != float64(2) {
+		t.Errorf("version 1: %v", doc)
+	}
+
+	current := `{"version": 2, "b": "z"}`
+	if out, err = Upgrade([]byte(current), migrations); err != nil || string(out) != current {
+		t.Errorf("current version: %s, %v", out, err)
+	}
+
+	if _, err := Upgrade([]byte(`{"version": 3}`), migrations); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("err = %v, want ErrNewerVersion", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package listing filters, sorts and windows post and draft listings on the
+// client, for the criteria Substack's list endpoints cannot apply themselves.
+package listing
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// Item is the subset of a post or draft that filters and sorts look at.
+type Item struct {
+	ID        int
+	Title     string
+	Subtitle  string
+	Slug      string
+	Audience  string
+	Section   string
+	Date      time.Time
+	WordCount int
+}
+
+// FromPost describes a post by its publish date.
+func FromPost(p model.Post) Item {
+	date, err := time.Parse(time.RFC3339, p.PostDate)
+	if err != nil {
+		date = p.DraftCreated
+	}
+	return Item{
+		ID: p.ID, Title: p.Title, Subtitle: p.Subtitle, Slug: p.Slug, Audience: p.Audience,
+		Section: p.SectionID.String(), Date: date, WordCount: p.WordCount,
+	}
+}
+
+// FromDraft describes a draft by its last edit.
+func FromDraft(d model.DraftResponse) Item {
+	date := d.DraftUpdated
+	if date.IsZero() {
+		date = d.DraftCreated
+	}
+	return Item{
+		ID: d.ID, Title: d.Title, Subtitle: d.Subtitle, Slug: d.Slug, Audience: d.Audience,
+		Section: d.SectionID.String(), Date: date, WordCount: d.WordCount,
+	}
+}
+
+// FromScheduled describes a scheduled draft by the time it will be
+// published.
+func FromScheduled(d model.DraftResponse) Item {
+	it := FromDraft(d)
+	if at, err := time.Parse(time.RFC3339, d.PostDate); err == nil {
+		it.Date = at
+	}
+	return it
+}
+
+// Filter selects items. Zero fields match everything. Since is inclusive and
+// Until exclusive.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Audience string
+	Section  string
+	Search   string // case-insensitive match on title, subtitle or slug
+}
+
+func (f Filter) IsZero() bool {
+	return f == Filter{}
+}
+
+func (f Filter) Match(it Item) bool {
+	if !f.Since.IsZero() && it.Date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !it.Date.Before(f.Until) {
+		return false
+	}
+	if f.Audience != "" && it.Audience != f.Audience {
+		return false
+	}
+	if f.Section != "" && it.Section != f.Section {
+		return false
+	}
+	if f.Search != "" {
+		needle := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(it.Title), needle) &&
+			!strings.Contains(strings.ToLower(it.Subtitle), needle) &&
+			!strings.Contains(strings.ToLower(it.Slug), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortKeys lists the accepted sort keys. Dates sort newest first, titles
+// A to Z and word counts longest first.
+var SortKeys = []string{"date", "title", "words"}
+
+// Sort orders items in place by key, or keeps their order when key is
+// empty. Reverse flips the result. Ties keep their original order.
+func Sort[T any](items []T, describe func(T) Item, key string, reverse bool) error {
+	var compare func(a, b Item) int
+	switch key {
+	case "":
+	case "date":
+		compare = func(a, b Item) int { return b.Date.Compare(a.Date) }
+	case "title":
+		compare = func(a, b Item) int { return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) }
+	case "words":
+		compare = func(a, b Item) int { return cmp.Compare(b.WordCount, a.WordCount) }
+	default:
+		return fmt.Errorf("unknown sort key %q (valid: %s)", key, strings.Join(SortKeys, ", "))
+	}
+	if compare != nil {
+		slices.SortStableFunc(items, func(a, b T) int { return compare(describe(a), describe(b)) })
+	}
+	if reverse {
+		slices.Reverse(items)
+	}
+	return nil
+}
+
+// Window returns at most limit items starting at offset. A zero limit means
+// no limit.
+func Window[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,125 @@
+package listing
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+var items = []Item{
+	{
+		ID: 1, Title: "Go tips", Slug: "go-tips", Audience: "everyone", Section: "10",
+		Date: day("2024-01-05"), WordCount: 300,
+	},
+	{ID: 2, Title: "apples", Subtitle: "About Go", Audience: "only_paid", Date: day("2024-02-01"), WordCount: 900},
+	{ID: 3, Title: "Zebra", Slug: "zebra-go-round", Audience: "everyone", Section: "10", Date: day("2024-03-10")},
+	{ID: 4, Title: "Rust", Audience: "everyone", Date: day("2024-03-11"), WordCount: 50},
+}
+
+func ids(items []Item) []int {
+	out := make([]int, len(items))
+	for i, it := range items {
+		out[i] = it.ID
+	}
+	return out
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int
+	}{
+		{"zero", Filter{}, []int{1, 2, 3, 4}},
+		{"since", Filter{Since: day("2024-02-01")}, []int{2, 3, 4}},
+		{"until", Filter{Until: day("2024-03-10")}, []int{1, 2}},
+		{"range", Filter{Since: day("2024-01-06"), Until: day("2024-03-11")}, []int{2, 3}},
+		{"audience", Filter{Audience: "only_paid"}, []int{2}},
+		{"section", Filter{Section: "10"}, []int{1, 3}},
+		{"search title subtitle slug", Filter{Search: "GO"}, []int{1, 2, 3}},
+		{"combined", Filter{Search: "go", Audience: "everyone", Since: day("2024-02-01")}, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, it := range items {
+				if tt.filter.Match(it) {
+					got = append(got, it.ID)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	self := func(it Item) Item { return it }
+	tests := []struct {
+		key     string
+		reverse bool
+		want    []int
+	}{
+		{"", false, []int{1, 2, 3, 4}},
+		{"", true, []int{4, 3, 2, 1}},
+		{"date", false, []int{4, 3, 2, 1}},
+		{"date", true, []int{1, 2, 3, 4}},
+		{"title", false, []int{2, 1, 4, 3}},
+		{"words", false, []int{2, 1, 4, 3}},
+		{"words", true, []int{3, 4, 1, 2}},
+	}
+	for _, tt := range tests {
+		sorted := append([]Item(nil), items...)
+		if err := Sort(sorted, self, tt.key, tt.reverse); err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(sorted); !slices.Equal(got, tt.want) {
+			t.Errorf("Sort(%q, %v) = %v, want %v", tt.key, tt.reverse, got, tt.want)
+		}
+	}
+	if err := Sort(items, self, "size", false); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		offset, limit int
+		want          []int
+	}{
+		{0, 0, []int{1, 2, 3, 4}},
+		{1, 2, []int{2, 3}},
+		{3, 10, []int{4}},
+		{9, 1, []int{}},
+	}
+	for _, tt := range tests {
+		if got := ids(Window(items, tt.offset, tt.limit)); !slices.Equal(got, tt.want) {
+			t.Errorf("Window(%d, %d) = %v, want %v", tt.offset, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestFromPostAndDraft(t *testing.T) {
+	post := FromPost(model.Post{ID: 1, PostDate: "2024-03-01T09:00:00Z", SectionID: json.Number("7")})
+	if !post.Date.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)) || post.Section != "7" {
+		t.Errorf("post item = %+v", post)
+	}
+	created := day("2024-01-01")
+	draft := FromDraft(model.DraftResponse{ID: 2, DraftCreated: created})
+	if !draft.Date.Equal(created) {
+		t.Errorf("draft date = %v, want creation date", draft.Date)
+	}
+	scheduled := FromScheduled(model.DraftResponse{ID: 3, DraftCreated: created, PostDate: "2030-05-01T08:00:00Z"})
+	if !scheduled.Date.Equal(time.Date(2030, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("scheduled date = %v, want the publish time", scheduled.Date)
+	}
+}
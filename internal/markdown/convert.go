@@ -10,120 +10,29 @@ import (
 	"github.com/yuin/goldmark/extension"
 	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
-// Frontmatter holds parsed YAML frontmatter fields.
-type Frontmatter struct {
-	Title           string
-	Subtitle        string
-	Date            string
-	Tags            []string
-	Audience        string
-	Draft           bool
-	Slug            string
-	CanonicalURL    string
-	MetaDescription string
-	SocialImage     string
-	ScheduledAt     string
-	Section         string
-	PodcastURL      string
-}
-
-// ParseFrontmatter extracts YAML frontmatter and returns it along with the
-// remaining body. If no frontmatter is present, returns nil and the full source.
-func ParseFrontmatter(source []byte) (*Frontmatter, []byte) {
-	s := string(source)
-	if !strings.HasPrefix(s, "---\n") {
-		return nil, source
-	}
-	end := strings.Index(s[4:], "\n---")
-	if end < 0 {
-		return nil, source
-	}
-	yamlBlock := s[4 : 4+end]
-	body := []byte(s[4+end+4:]) // skip past closing "---\n"
-
-	fm := &Frontmatter{}
-	for line := range strings.SplitSeq(yamlBlock, "\n") {
-		key, val, ok := parseYAMLLine(line)
-		if !ok {
-			continue
-		}
-		switch key {
-		case "title":
-			fm.Title = val
-		case "subtitle":
-			fm.Subtitle = val
-		case "date":
-			fm.Date = val
-		case "tags":
-			fm.Tags = parseYAMLList(val)
-		case "audience":
-			fm.Audience = val
-		case "draft":
-			fm.Draft = val == "true"
-		case "slug":
-			fm.Slug = val
-		case "canonical_url":
-			fm.CanonicalURL = val
-		case "meta_description":
-			fm.MetaDescription = val
-		case "social_image":
-			fm.SocialImage = val
-		case "scheduled_at":
-			fm.ScheduledAt = val
-		case "section":
-			fm.Section = val
-		case "podcast_url":
-			fm.PodcastURL = val
-		}
-	}
-	return fm, body
-}
-
-func parseYAMLLine(line string) (string, string, bool) {
-	key, val, found := strings.Cut(line, ":")
-	if !found {
-		return "", "", false
-	}
-	key = strings.TrimSpace(key)
-	val = strings.TrimSpace(val)
-	// Strip surrounding quotes
-	val = strings.Trim(val, "\"'")
-	return key, val, true
-}
-
-func parseYAMLList(val string) []string {
-	// Handle [a, b, c] inline syntax
-	val = strings.Trim(val, "[]")
-	parts := strings.Split(val, ",")
-	var result []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		p = strings.Trim(p, "\"'")
-		if p != "" {
-			result = append(result, p)
-		}
-	}
-	return result
-}
-
 // Convert parses markdown source (with optional frontmatter) and returns (title, substackBody).
 // The first H1 is extracted as the title (if present and frontmatter has no title).
+// Frontmatter errors are ignored; use ConvertWithFrontmatter to surface them.
 func Convert(source []byte) (string, model.DraftBody) {
-	_, body := ParseFrontmatter(source)
+	_, body, _ := ParseFrontmatter(source)
 	return convertBody(body)
 }
 
 // ConvertWithFrontmatter parses markdown source, returning frontmatter, title, and body.
 // Title priority: frontmatter title > first H1.
-func ConvertWithFrontmatter(source []byte) (*Frontmatter, string, model.DraftBody) {
-	fm, body := ParseFrontmatter(source)
+func ConvertWithFrontmatter(source []byte) (*Frontmatter, string, model.DraftBody, error) {
+	fm, body, err := ParseFrontmatter(source)
+	if err != nil {
+		return nil, "", model.DraftBody{}, err
+	}
 	title, draftBody := convertBody(body)
 	if fm != nil && fm.Title != "" {
 		title = fm.Title
 	}
-	return fm, title, draftBody
+	return fm, title, draftBody, nil
 }
 
 func convertBody(source []byte) (string, model.DraftBody) {
@@ -137,37 +46,31 @@ func convertBody(source []byte) (string, model.DraftBody) {
 
 	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
 		if h, ok := child.(*ast.Heading); ok && h.Level == 1 && title == "" {
-			title = string(nodeText(child, source))
+			title = string(unescape(nodeText(child, source)))
 			continue
 		}
-		n := convertBlock(child, source)
-		if n != nil {
-			nodes = append(nodes, *n)
-		}
+		nodes = append(nodes, convertBlock(child, source)...)
 	}
 
 	return title, model.DraftBody{Type: "doc", Content: nodes}
 }
 
-func convertBlock(node ast.Node, source []byte) *model.Node {
+func convertBlock(node ast.Node, source []byte) []model.Node {
 	switch n := node.(type) {
 	case *ast.Heading:
-		return &model.Node{
+		return []model.Node{{
 			Type:    "heading",
 			Attrs:   map[string]any{"level": n.Level},
 			Content: convertInlineChildren(n, source),
-		}
+		}}
 	case *ast.Paragraph:
-		content := convertInlineChildren(n, source)
-		return &model.Node{Type: "paragraph", Content: content}
+		return convertParagraph(n, source)
 	case *ast.Blockquote:
 		var children []model.Node
 		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
-			if cn := convertBlock(c, source); cn != nil {
-				children = append(children, *cn)
-			}
+			children = append(children, convertBlock(c, source)...)
 		}
-		return &model.Node{Type: "blockquote", Content: children}
+		return []model.Node{{Type: "blockquote", Content: children}}
 	case *ast.FencedCodeBlock:
 		lang := string(n.Language(source))
 		attrs := map[string]any{}
@@ -175,11 +78,11 @@ func convertBlock(node ast.Node, source []byte) *model.Node {
 			attrs["language"] = lang
 		}
 		code := codeBlockText(n, source)
-		return &model.Node{
+		return []model.Node{{
 			Type:    "code_block",
 			Attrs:   attrs,
 			Content: []model.Node{{Type: "text", Text: code}},
-		}
+		}}
 	case *ast.List:
 		typ := "bullet_list"
 		if n.IsOrdered() {
@@ -187,31 +90,123 @@ func convertBlock(node ast.Node, source []byte) *model.Node {
 		}
 		var items []model.Node
 		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
-			if item := convertListItem(c, source); item != nil {
-				items = append(items, *item)
-			}
+			items = append(items, convertListItem(c, source))
 		}
-		return &model.Node{Type: typ, Content: items}
+		return []model.Node{{Type: typ, Content: items}}
 	case *ast.ThematicBreak:
-		return &model.Node{Type: "horizontal_rule"}
+		return []model.Node{{Type: "horizontal_rule"}}
 	default:
 		// Handle TextBlock and other container nodes (e.g. tight list items)
-		content := convertInlineChildren(node, source)
-		if len(content) > 0 {
-			return &model.Node{Type: "paragraph", Content: content}
-		}
-		return nil
+		return convertParagraph(node, source)
 	}
 }
 
-func convertListItem(node ast.Node, source []byte) *model.Node {
+func convertListItem(node ast.Node, source []byte) model.Node {
 	var children []model.Node
 	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
-		if cn := convertBlock(c, source); cn != nil {
-			children = append(children, *cn)
+		children = append(children, convertBlock(c, source)...)
+	}
+	return model.Node{Type: "list_item", Content: children}
+}
+
+// convertParagraph converts a paragraph-like container. Substack images are
+// block nodes, so any image in the paragraph splits it: the surrounding text
+// becomes separate paragraphs and the image becomes a captioned image block.
+func convertParagraph(node ast.Node, source []byte) []model.Node {
+	var blocks []model.Node
+	var inline []model.Node
+	flush := func() {
+		if !isBlank(inline) {
+			blocks = append(blocks, model.Node{Type: "paragraph", Content: trimEdges(inline)})
+		}
+		inline = nil
+	}
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		if img, href := imageChild(c); img != nil {
+			flush()
+			blocks = append(blocks, captionedImage(img, href, source))
+			continue
 		}
+		inline = append(inline, convertInline(c, source, nil)...)
 	}
-	return &model.Node{Type: "list_item", Content: children}
+	flush()
+	return blocks
+}
+
+// imageChild reports whether node is an image, or a link wrapping only an
+// image, returning the image and the link destination if any.
+func imageChild(node ast.Node) (*ast.Image, string) {
+	switch n := node.(type) {
+	case *ast.Image:
+		return n, ""
+	case *ast.Link:
+		if img, ok := n.FirstChild().(*ast.Image); ok && n.ChildCount() == 1 {
+			return img, string(n.Destination)
+		}
+	}
+	return nil, ""
+}
+
+func captionedImage(img *ast.Image, href string, source []byte) model.Node {
+	attrs := map[string]any{
+		"src": string(img.Destination),
+		"alt": string(unescape(nodeText(img, source))),
+	}
+	if href != "" {
+		attrs["href"] = href
+	}
+	content := []model.Node{{Type: "image2", Attrs: attrs}}
+	if caption := string(img.Title); caption != "" {
+		attrs["title"] = caption
+		content = append(content, model.Node{
+			Type:    "caption",
+			Content: []model.Node{{Type: "text", Text: caption}},
+		})
+	}
+	return model.Node{Type: "captionedImage", Content: content}
+}
+
+// trimEdges drops whitespace left at the edges of a paragraph split around an image.
+func trimEdges(nodes []model.Node) []model.Node {
+	for len(nodes) > 0 && strings.TrimSpace(nodes[0].Text) == "" {
+		nodes = nodes[1:]
+	}
+	for len(nodes) > 0 && strings.TrimSpace(nodes[len(nodes)-1].Text) == "" {
+		nodes = nodes[:len(nodes)-1]
+	}
+	nodes[0].Text = strings.TrimLeft(nodes[0].Text, " \t\n")
+	nodes[len(nodes)-1].Text = strings.TrimRight(nodes[len(nodes)-1].Text, " \t\n")
+	return nodes
+}
+
+func isBlank(nodes []model.Node) bool {
+	for _, n := range nodes {
+		if strings.TrimSpace(n.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// WalkImages calls fn with the attrs of every image node in body, in document
+// order. The attrs map is shared with the body, so fn may rewrite src, width,
+// height and similar fields in place.
+func WalkImages(body model.DraftBody, fn func(attrs map[string]any) error) error {
+	return walkImages(body.Content, fn)
+}
+
+func walkImages(nodes []model.Node, fn func(attrs map[string]any) error) error {
+	for _, n := range nodes {
+		if n.Type == "image2" && n.Attrs != nil {
+			if err := fn(n.Attrs); err != nil {
+				return err
+			}
+		}
+		if err := walkImages(n.Content, fn); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func convertInlineChildren(node ast.Node, source []byte) []model.Node {
@@ -225,7 +220,7 @@ func convertInlineChildren(node ast.Node, source []byte) []model.Node {
 func convertInline(node ast.Node, source []byte, marks []model.Mark) []model.Node {
 	switch n := node.(type) {
 	case *ast.Text:
-		t := string(n.Value(source))
+		t := string(unescape(n.Value(source)))
 		result := []model.Node{{Type: "text", Text: t, Marks: marks}}
 		if n.SoftLineBreak() {
 			result[0].Text += "\n"
@@ -299,6 +294,14 @@ func nodeText(n ast.Node, source []byte) []byte {
 	return buf
 }
 
+// unescape resolves backslash escapes and entity references, which goldmark
+// leaves in the raw text segments.
+func unescape(b []byte) []byte {
+	b = util.UnescapePunctuations(b)
+	b = util.ResolveNumericReferences(b)
+	return util.ResolveEntityNames(b)
+}
+
 func codeBlockText(n *ast.FencedCodeBlock, source []byte) string {
 	var buf []byte
 	lines := n.Lines()
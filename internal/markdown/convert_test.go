@@ -144,3 +144,83 @@ func TestConvert_HorizontalRule(t *testing.T) {
 		t.Errorf("type = %q, want horizontal_rule", body.Content[0].Type)
 	}
 }
+
+func TestConvert_Image(t *testing.T) {
+	src := []byte("![A chart](./chart.png \"Quarterly growth\")\n")
+	_, body := Convert(src)
+	if len(body.Content) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(body.Content))
+	}
+	n := body.Content[0]
+	if n.Type != "captionedImage" {
+		t.Fatalf("type = %q, want captionedImage", n.Type)
+	}
+	img := n.Content[0]
+	if img.Type != "image2" {
+		t.Errorf("image type = %q, want image2", img.Type)
+	}
+	if img.Attrs["src"] != "./chart.png" || img.Attrs["alt"] != "A chart" {
+		t.Errorf("attrs = %+v", img.Attrs)
+	}
+	if len(n.Content) != 2 || n.Content[1].Type != "caption" {
+		t.Fatalf("expected caption node, got %+v", n.Content)
+	}
+	if n.Content[1].Content[0].Text != "Quarterly growth" {
+		t.Errorf("caption = %q", n.Content[1].Content[0].Text)
+	}
+}
+
+func TestConvert_ImageSplitsParagraph(t *testing.T) {
+	src := []byte("Before ![img](a.png) after\n")
+	_, body := Convert(src)
+	want := []string{"paragraph", "captionedImage", "paragraph"}
+	if len(body.Content) != len(want) {
+		t.Fatalf("nodes = %+v", body.Content)
+	}
+	for i, typ := range want {
+		if body.Content[i].Type != typ {
+			t.Errorf("node %d type = %q, want %q", i, body.Content[i].Type, typ)
+		}
+	}
+}
+
+func TestConvert_LinkedImage(t *testing.T) {
+	src := []byte("[![img](a.png)](https://example.com)\n")
+	_, body := Convert(src)
+	img := body.Content[0].Content[0]
+	if img.Attrs["href"] != "https://example.com" {
+		t.Errorf("href = %v", img.Attrs["href"])
+	}
+}
+
+func TestWalkImages(t *testing.T) {
+	src := []byte("![a](a.png)\n\n> ![b](b.png)\n")
+	_, body := Convert(src)
+	var seen []string
+	err := WalkImages(body, func(attrs map[string]any) error {
+		seen = append(seen, attrs["src"].(string))
+		attrs["src"] = "https://cdn.example.com/" + attrs["src"].(string)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 || seen[0] != "a.png" || seen[1] != "b.png" {
+		t.Errorf("seen = %v", seen)
+	}
+	if got := body.Content[0].Content[0].Attrs["src"]; got != "https://cdn.example.com/a.png" {
+		t.Errorf("src not rewritten: %v", got)
+	}
+}
+
+func TestConvert_BackslashEscapes(t *testing.T) {
+	src := []byte("\\*not emphasis\\* &amp; more\n")
+	_, body := Convert(src)
+	var got string
+	for _, n := range body.Content[0].Content {
+		got += n.Text
+	}
+	if got != "*not emphasis* & more" {
+		t.Errorf("text = %q", got)
+	}
+}
@@ -0,0 +1,440 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter holds parsed frontmatter fields.
+type Frontmatter struct {
+	Title           string
+	Subtitle        string
+	Date            string
+	Tags            []string
+	Audience        string
+	Draft           bool
+	Slug            string
+	CanonicalURL    string
+	MetaDescription string
+	SEOTitle        string
+	SocialImage     string
+	ScheduledAt     string
+	Section         string
+	PodcastURL      string
+	// RemoteUpdatedAt records when the remote draft was last modified at the
+	// time the file was pulled, so updates can detect remote edits.
+	RemoteUpdatedAt string
+
+	// Extra holds keys this package does not recognize, keyed by name, so
+	// extensions can read their own settings from the same block.
+	Extra map[string]any
+	// Warnings lists non-fatal problems such as unknown keys.
+	Warnings []string
+}
+
+// FrontmatterError reports a frontmatter problem. Line is 1-based and counts
+// from the top of the markdown file, or 0 when the position is unknown.
+type FrontmatterError struct {
+	Line int
+	Key  string
+	Msg  string
+}
+
+func (e *FrontmatterError) Error() string {
+	var b strings.Builder
+	b.WriteString("frontmatter")
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " line %d", e.Line)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(&b, " (%s)", e.Key)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// fmEntry is a single top-level frontmatter key, normalized across formats.
+// Scalars are strings or bools, sequences are []any and mappings map[string]any.
+type fmEntry struct {
+	key   string
+	value any
+	line  int
+}
+
+// ParseFrontmatter extracts frontmatter and returns it along with the remaining
+// body. YAML (--- delimited), TOML (+++ delimited) and JSON (a leading object
+// with "{" on its own line) are supported. If no frontmatter is present,
+// returns nil and the full source. Parse failures return a *FrontmatterError.
+func ParseFrontmatter(source []byte) (*Frontmatter, []byte, error) {
+	format, block, blockLine, body, err := splitFrontmatter(source)
+	if err != nil {
+		return nil, source, err
+	}
+	var entries []fmEntry
+	switch format {
+	case "":
+		return nil, source, nil
+	case "yaml":
+		entries, err = yamlEntries(block, blockLine)
+	case "toml":
+		entries, err = tomlEntries(block, blockLine)
+	case "json":
+		entries, err = jsonEntries(block, blockLine)
+	}
+	if err != nil {
+		return nil, body, err
+	}
+
+	fm := &Frontmatter{}
+	seen := map[string]int{}
+	for _, e := range entries {
+		if prev, dup := seen[e.key]; dup {
+			return nil, body, &FrontmatterError{
+				Line: e.line, Key: e.key, Msg: fmt.Sprintf("duplicate key (first defined on line %d)", prev),
+			}
+		}
+		seen[e.key] = e.line
+		if setErr := fm.set(e); setErr != nil {
+			return nil, body, setErr
+		}
+	}
+	return fm, body, nil
+}
+
+// splitFrontmatter locates the frontmatter block. blockLine is the file line
+// on which the block's first line sits.
+func splitFrontmatter(source []byte) (string, []byte, int, []byte, error) {
+	first, _, _ := bytes.Cut(source, []byte("\n"))
+	switch string(bytes.TrimRight(first, "\r")) {
+	case "---":
+		return splitDelimited(source, "---", "yaml")
+	case "+++":
+		return splitDelimited(source, "+++", "toml")
+	case "{":
+		dec := json.NewDecoder(bytes.NewReader(source))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return "", nil, 0, nil, jsonError(err, source, 1)
+		}
+		end := int(dec.InputOffset())
+		return "json", source[:end], 1, bytes.TrimPrefix(source[end:], []byte("\n")), nil
+	default:
+		return "", nil, 0, source, nil
+	}
+}
+
+func splitDelimited(source []byte, delim, format string) (string, []byte, int, []byte, error) {
+	_, rest, _ := bytes.Cut(source, []byte("\n"))
+	offset := len(source) - len(rest)
+	for pos := 0; pos <= len(rest); {
+		line, _, found := bytes.Cut(rest[pos:], []byte("\n"))
+		if string(bytes.TrimRight(line, "\r")) == delim {
+			end := pos + len(line)
+			if found {
+				end++
+			}
+			return format, rest[:pos], 2, source[offset+end:], nil
+		}
+		if !found {
+			break
+		}
+		pos += len(line) + 1
+	}
+	return "", nil, 0, nil, &FrontmatterError{Line: 1, Msg: fmt.Sprintf("missing closing %q delimiter", delim)}
+}
+
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func yamlEntries(block []byte, blockLine int) ([]fmEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(block, &doc); err != nil {
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &FrontmatterError{Line: line + blockLine - 1, Msg: m[2]}
+		}
+		return nil, &FrontmatterError{Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &FrontmatterError{Line: root.Line + blockLine - 1, Msg: "expected key: value pairs"}
+	}
+	entries := make([]fmEntry, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		entries = append(entries, fmEntry{key: k.Value, value: yamlValue(v), line: k.Line + blockLine - 1})
+	}
+	return entries, nil
+}
+
+func yamlValue(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		switch n.Tag {
+		case "!!null":
+			return nil
+		case "!!bool":
+			var b bool
+			if err := n.Decode(&b); err == nil {
+				return b
+			}
+		}
+		// Keep the literal text so dates and numbers are not reformatted.
+		return n.Value
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			items = append(items, yamlValue(c))
+		}
+		return items
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			m[n.Content[i].Value] = yamlValue(n.Content[i+1])
+		}
+		return m
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return yamlValue(n.Content[0])
+		}
+	}
+	return nil
+}
+
+func tomlEntries(block []byte, blockLine int) ([]fmEntry, error) {
+	var raw map[string]any
+	md, err := toml.Decode(string(block), &raw)
+	if err != nil {
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			return nil, &FrontmatterError{Line: pe.Position.Line + blockLine - 1, Msg: pe.Message}
+		}
+		return nil, &FrontmatterError{Msg: err.Error()}
+	}
+	lines := strings.Split(string(block), "\n")
+	var entries []fmEntry
+	for _, key := range md.Keys() {
+		if len(key) != 1 {
+			continue
+		}
+		entries = append(entries, fmEntry{
+			key:   key[0],
+			value: normalizeValue(raw[key[0]]),
+			line:  tomlKeyLine(lines, key[0], blockLine),
+		})
+	}
+	return entries, nil
+}
+
+// tomlKeyLine finds the file line defining a top-level key, since the TOML
+// decoder does not expose key positions. Returns 0 if not found.
+func tomlKeyLine(lines []string, key string, blockLine int) int {
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if name, _, ok := strings.Cut(l, "="); ok && strings.Trim(strings.TrimSpace(name), `"'`) == key {
+			return i + blockLine
+		}
+		if l == "["+key+"]" {
+			return i + blockLine
+		}
+	}
+	return 0
+}
+
+func jsonEntries(block []byte, blockLine int) ([]fmEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(block))
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonError(err, block, blockLine)
+	}
+	var entries []fmEntry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, jsonError(err, block, blockLine)
+		}
+		key, _ := tok.(string)
+		line := lineAt(block, int(dec.InputOffset())) + blockLine - 1
+		var v any
+		if decodeErr := dec.Decode(&v); decodeErr != nil {
+			return nil, jsonError(decodeErr, block, blockLine)
+		}
+		entries = append(entries, fmEntry{key: key, value: normalizeValue(v), line: line})
+	}
+	return entries, nil
+}
+
+func jsonError(err error, block []byte, blockLine int) error {
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		return &FrontmatterError{Line: lineAt(block, int(se.Offset)) + blockLine - 1, Msg: se.Error()}
+	}
+	return &FrontmatterError{Msg: err.Error()}
+}
+
+func lineAt(b []byte, offset int) int {
+	offset = min(offset, len(b))
+	return bytes.Count(b[:offset], []byte("\n")) + 1
+}
+
+// normalizeValue converts TOML and JSON values to the shapes yamlValue
+// produces, so all formats share one set of field setters.
+func normalizeValue(v any) any {
+	switch x := v.(type) {
+	case string, bool, nil:
+		return x
+	case time.Time:
+		switch x.Location().String() {
+		case "date-local":
+			return x.Format(time.DateOnly)
+		case "datetime-local":
+			return x.Format("2006-01-02T15:04:05")
+		}
+		return x.Format(time.RFC3339)
+	case []any:
+		items := make([]any, 0, len(x))
+		for _, item := range x {
+			items = append(items, normalizeValue(item))
+		}
+		return items
+	case []map[string]any:
+		items := make([]any, 0, len(x))
+		for _, item := range x {
+			items = append(items, normalizeValue(item))
+		}
+		return items
+	case map[string]any:
+		m := make(map[string]any, len(x))
+		for k, item := range x {
+			m[k] = normalizeValue(item)
+		}
+		return m
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func (fm *Frontmatter) set(e fmEntry) error {
+	switch e.key {
+	case "title":
+		return setString(&fm.Title, e)
+	case "subtitle":
+		return setString(&fm.Subtitle, e)
+	case "date":
+		return setString(&fm.Date, e)
+	case "tags":
+		return setList(&fm.Tags, e)
+	case "audience":
+		return setString(&fm.Audience, e)
+	case "draft":
+		return setBool(&fm.Draft, e)
+	case "slug":
+		return setString(&fm.Slug, e)
+	case "canonical_url":
+		return setString(&fm.CanonicalURL, e)
+	case "meta_description":
+		return setString(&fm.MetaDescription, e)
+	case "seo_title":
+		return setString(&fm.SEOTitle, e)
+	case "social_image":
+		return setString(&fm.SocialImage, e)
+	case "scheduled_at":
+		return setString(&fm.ScheduledAt, e)
+	case "section":
+		return setString(&fm.Section, e)
+	case "podcast_url":
+		return setString(&fm.PodcastURL, e)
+	case "remote_updated_at":
+		return setString(&fm.RemoteUpdatedAt, e)
+	default:
+		if fm.Extra == nil {
+			fm.Extra = map[string]any{}
+		}
+		fm.Extra[e.key] = e.value
+		if e.line > 0 {
+			fm.Warnings = append(fm.Warnings, fmt.Sprintf("line %d: unknown frontmatter key %q", e.line, e.key))
+		} else {
+			fm.Warnings = append(fm.Warnings, fmt.Sprintf("unknown frontmatter key %q", e.key))
+		}
+		return nil
+	}
+}
+
+func typeError(e fmEntry, want string) error {
+	got := "a value"
+	switch e.value.(type) {
+	case []any:
+		got = "a list"
+	case map[string]any:
+		got = "nested keys"
+	case bool:
+		got = "a boolean"
+	case string:
+		got = "a string"
+	}
+	return &FrontmatterError{Line: e.line, Key: e.key, Msg: fmt.Sprintf("expected %s, got %s", want, got)}
+}
+
+func setString(dst *string, e fmEntry) error {
+	switch v := e.value.(type) {
+	case nil:
+		return nil
+	case string:
+		*dst = v
+	case bool:
+		*dst = strconv.FormatBool(v)
+	default:
+		return typeError(e, "a string")
+	}
+	return nil
+}
+
+func setBool(dst *bool, e fmEntry) error {
+	switch v := e.value.(type) {
+	case nil:
+		return nil
+	case bool:
+		*dst = v
+		return nil
+	default:
+		return typeError(e, "true or false")
+	}
+}
+
+// setList accepts a list of scalars, or a single comma-separated string.
+func setList(dst *[]string, e fmEntry) error {
+	switch v := e.value.(type) {
+	case nil:
+		return nil
+	case string:
+		for p := range strings.SplitSeq(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				*dst = append(*dst, p)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return typeError(e, "a list of strings")
+			}
+			*dst = append(*dst, s)
+		}
+	default:
+		return typeError(e, "a list")
+	}
+	return nil
+}
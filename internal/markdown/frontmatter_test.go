@@ -0,0 +1,163 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontmatter_None(t *testing.T) {
+	src := []byte("# Title\n\nBody\n")
+	fm, body, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm != nil {
+		t.Errorf("fm = %+v, want nil", fm)
+	}
+	if string(body) != string(src) {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestParseFrontmatter_MetadataFields(t *testing.T) {
+	src := []byte("---\nslug: my-post\ncanonical_url: https://blog.example.com/p\nmeta_description: About things\n" +
+		"seo_title: Things\nsocial_image: ./cover.png\ntags: [go, cli]\ndate: 2024-01-15\n" +
+		"podcast_url: https://example.com/ep.mp3\n---\nBody\n")
+	fm, body, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Slug != "my-post" || fm.CanonicalURL != "https://blog.example.com/p" || fm.MetaDescription != "About things" {
+		t.Errorf("fm = %+v", fm)
+	}
+	if fm.SEOTitle != "Things" || fm.SocialImage != "./cover.png" || fm.Date != "2024-01-15" {
+		t.Errorf("fm = %+v", fm)
+	}
+	if fm.PodcastURL != "https://example.com/ep.mp3" || len(fm.Tags) != 2 {
+		t.Errorf("fm = %+v", fm)
+	}
+	if string(body) != "Body\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestParseFrontmatter_YAMLFeatures(t *testing.T) {
+	src := []byte(`---
+title: "She said \"hi\": a story"  # trailing comment
+subtitle: >
+  A folded
+  subtitle
+tags:
+  - go
+  - cli
+draft: true
+---
+Body
+`)
+	fm, _, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != `She said "hi": a story` {
+		t.Errorf("title = %q", fm.Title)
+	}
+	if fm.Subtitle != "A folded subtitle\n" {
+		t.Errorf("subtitle = %q", fm.Subtitle)
+	}
+	if len(fm.Tags) != 2 || fm.Tags[0] != "go" || fm.Tags[1] != "cli" {
+		t.Errorf("tags = %v", fm.Tags)
+	}
+	if !fm.Draft {
+		t.Error("draft = false, want true")
+	}
+}
+
+func TestParseFrontmatter_UnknownKeys(t *testing.T) {
+	src := []byte("---\ntitle: T\nseries:\n  name: Go\n  part: 2\n---\n")
+	fm, _, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series, ok := fm.Extra["series"].(map[string]any)
+	if !ok || series["name"] != "Go" || series["part"] != "2" {
+		t.Errorf("extra = %+v", fm.Extra)
+	}
+	if len(fm.Warnings) != 1 || !strings.Contains(fm.Warnings[0], "line 3") {
+		t.Errorf("warnings = %v", fm.Warnings)
+	}
+}
+
+func TestParseFrontmatter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		key  string
+	}{
+		{"syntax", "---\ntitle: T\n  bad: indent\n---\n", 3, ""},
+		{"wrong type", "---\ntitle: T\ndraft: maybe\n---\n", 3, "draft"},
+		{"nested known key", "---\ntitle:\n  text: T\n---\n", 2, "title"},
+		{"duplicate", "---\ntitle: A\ntitle: B\n---\n", 3, "title"},
+		{"unterminated", "---\ntitle: T\n", 1, ""},
+		{"toml syntax", "+++\ntitle = \n+++\n", 2, ""},
+		{"json syntax", "{\n  \"title\": \"T\",\n}\n", 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseFrontmatter([]byte(tt.src))
+			var fe *FrontmatterError
+			if !errors.As(err, &fe) {
+				t.Fatalf("err = %v, want *FrontmatterError", err)
+			}
+			if fe.Line != tt.line || fe.Key != tt.key {
+				t.Errorf("line=%d key=%q, want line=%d key=%q (%v)", fe.Line, fe.Key, tt.line, tt.key, err)
+			}
+		})
+	}
+}
+
+func TestParseFrontmatter_TOML(t *testing.T) {
+	src := []byte("+++\ntitle = \"T\"\ntags = [\"a\", \"b\"]\ndate = 2024-01-15\ndraft = true\n+++\nBody\n")
+	fm, body, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != "T" || len(fm.Tags) != 2 || fm.Date != "2024-01-15" || !fm.Draft {
+		t.Errorf("fm = %+v", fm)
+	}
+	if string(body) != "Body\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestParseFrontmatter_JSON(t *testing.T) {
+	src := []byte("{\n  \"title\": \"T\",\n  \"tags\": [\"a\"],\n  \"extra\": 1\n}\nBody\n")
+	fm, body, err := ParseFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != "T" || len(fm.Tags) != 1 {
+		t.Errorf("fm = %+v", fm)
+	}
+	if len(fm.Warnings) != 1 || !strings.Contains(fm.Warnings[0], "line 4") {
+		t.Errorf("warnings = %v", fm.Warnings)
+	}
+	if string(body) != "Body\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestConvertWithFrontmatter_TitlePriority(t *testing.T) {
+	src := []byte("---\ntitle: From Frontmatter\n---\n# From Heading\n\nText\n")
+	fm, title, body, err := ConvertWithFrontmatter(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm == nil || title != "From Frontmatter" {
+		t.Errorf("title = %q", title)
+	}
+	if len(body.Content) != 1 {
+		t.Errorf("body = %+v", body.Content)
+	}
+}
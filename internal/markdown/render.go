@@ -0,0 +1,524 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Render converts a Substack document back to CommonMark. Node types with no
+// markdown equivalent (embeds, widgets) are rendered through their children,
+// or dropped when they have none.
+func Render(body model.DraftBody) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(renderBlocks(body.Content, "\n\n"))
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// RenderDocument renders a full markdown file: frontmatter (if fm is non-nil)
+// followed by the body.
+func RenderDocument(fm *Frontmatter, body model.DraftBody) ([]byte, error) {
+	var buf bytes.Buffer
+	if fm != nil {
+		head, err := MarshalFrontmatter(fm)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(head)
+		buf.WriteByte('\n')
+	}
+	buf.Write(Render(body))
+	return buf.Bytes(), nil
+}
+
+// MarshalFrontmatter encodes fm as a YAML frontmatter block, including the
+// --- delimiters. Empty fields are omitted; Extra keys follow in sorted order.
+func MarshalFrontmatter(fm *Frontmatter) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value any) error {
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return fmt.Errorf("encoding frontmatter %s: %w", key, err)
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+		return nil
+	}
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"title", fm.Title},
+		{"subtitle", fm.Subtitle},
+		{"slug", fm.Slug},
+		{"date", fm.Date},
+		{"audience", fm.Audience},
+		{"section", fm.Section},
+		{"scheduled_at", fm.ScheduledAt},
+		{"seo_title", fm.SEOTitle},
+		{"meta_description", fm.MetaDescription},
+		{"social_image", fm.SocialImage},
+		{"canonical_url", fm.CanonicalURL},
+		{"podcast_url", fm.PodcastURL},
+		{"remote_updated_at", fm.RemoteUpdatedAt},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := add(f.key, f.value); err != nil {
+			return nil, err
+		}
+	}
+	if len(fm.Tags) > 0 {
+		if err := add("tags", fm.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if fm.Draft {
+		if err := add("draft", true); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(fm.Extra)) {
+		if err := add(key, fm.Extra[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	if len(root.Content) > 0 {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("---\n")
+	return buf.Bytes(), nil
+}
+
+// DecodeBody parses a draft_body JSON string as returned by the API.
+func DecodeBody(raw string) (model.DraftBody, error) {
+	var body model.DraftBody
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		return body, fmt.Errorf("decoding document body: %w", err)
+	}
+	return body, nil
+}
+
+// FrontmatterFromPost reconstructs frontmatter from published post metadata.
+func FrontmatterFromPost(p model.Post) *Frontmatter {
+	return &Frontmatter{
+		Title:           p.Title,
+		Subtitle:        p.Subtitle,
+		Slug:            p.Slug,
+		Audience:        p.Audience,
+		Section:         p.SectionID.String(),
+		Date:            p.PostDate,
+		SEOTitle:        p.SearchTitle,
+		MetaDescription: p.SearchDescription,
+		SocialImage:     p.CoverImage,
+		CanonicalURL:    p.CanonicalURL,
+		RemoteUpdatedAt: formatRemoteTime(p.DraftUpdated),
+	}
+}
+
+// FrontmatterFromDraft reconstructs frontmatter from draft metadata. Drafts
+// are marked draft: true so re-running post create will not publish them.
+func FrontmatterFromDraft(d model.DraftResponse) *Frontmatter {
+	return &Frontmatter{
+		Title:           d.Title,
+		Subtitle:        d.Subtitle,
+		Slug:            d.Slug,
+		Audience:        d.Audience,
+		Section:         d.SectionID.String(),
+		SEOTitle:        d.SearchTitle,
+		MetaDescription: d.SearchDescription,
+		SocialImage:     d.CoverImage,
+		CanonicalURL:    d.CanonicalURL,
+		RemoteUpdatedAt: formatRemoteTime(d.DraftUpdated),
+		Draft:           true,
+	}
+}
+
+func formatRemoteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func renderBlocks(nodes []model.Node, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if s := renderBlock(n); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func renderBlock(n model.Node) string {
+	switch n.Type {
+	case "paragraph":
+		return escapeLineStarts(renderInline(n.Content))
+	case "heading":
+		level := min(max(intAttr(n.Attrs, "level", 1), 1), 6)
+		return strings.Repeat("#", level) + " " + renderInline(n.Content)
+	case "blockquote":
+		return prefixLines(renderBlocks(n.Content, "\n\n"), "> ", ">")
+	case "code_block":
+		return renderCodeBlock(n)
+	case "bullet_list", "ordered_list":
+		return renderList(n)
+	case "horizontal_rule":
+		return "---"
+	case "captionedImage":
+		return renderCaptionedImage(n)
+	case "image2":
+		return renderImage(n.Attrs, "")
+	default:
+		if len(n.Content) == 0 {
+			return ""
+		}
+		if isInline(n.Content) {
+			return renderInline(n.Content)
+		}
+		return renderBlocks(n.Content, "\n\n")
+	}
+}
+
+func renderCodeBlock(n model.Node) string {
+	var code strings.Builder
+	for _, c := range n.Content {
+		code.WriteString(c.Text)
+	}
+	text := strings.TrimSuffix(code.String(), "\n")
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	lang, _ := n.Attrs["language"].(string)
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+func renderList(n model.Node) string {
+	ordered := n.Type == "ordered_list"
+	start := intAttr(n.Attrs, "start", intAttr(n.Attrs, "order", 1))
+	loose := false
+	for _, item := range n.Content {
+		if len(item.Content) > 1 {
+			loose = true
+		}
+	}
+	items := make([]string, 0, len(n.Content))
+	for i, item := range n.Content {
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+		sep := "\n"
+		if loose {
+			sep = "\n\n"
+		}
+		first, rest, multiline := strings.Cut(renderBlocks(item.Content, sep), "\n")
+		entry := marker + first
+		if multiline {
+			entry += "\n" + prefixLines(rest, strings.Repeat(" ", len(marker)), "")
+		}
+		items = append(items, entry)
+	}
+	if loose {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+func renderCaptionedImage(n model.Node) string {
+	var attrs map[string]any
+	var caption string
+	for _, c := range n.Content {
+		switch c.Type {
+		case "image2":
+			attrs = c.Attrs
+		case "caption":
+			caption = plainText(c.Content)
+		}
+	}
+	if attrs == nil {
+		return ""
+	}
+	return renderImage(attrs, caption)
+}
+
+func renderImage(attrs map[string]any, caption string) string {
+	src, _ := attrs["src"].(string)
+	alt, _ := attrs["alt"].(string)
+	if caption == "" {
+		caption, _ = attrs["title"].(string)
+	}
+	img := "![" + escapeText(alt) + "](" + src
+	if caption != "" {
+		img += ` "` + strings.ReplaceAll(caption, `"`, `\"`) + `"`
+	}
+	img += ")"
+	if href, _ := attrs["href"].(string); href != "" {
+		img = "[" + img + "](" + href + ")"
+	}
+	return img
+}
+
+// renderInline serializes text nodes, opening and closing mark delimiters as
+// the active mark set changes so adjacent nodes share one pair of delimiters.
+func renderInline(nodes []model.Node) string {
+	nodes = mergeText(nodes)
+	var b strings.Builder
+	var open []model.Mark
+	for i, n := range nodes {
+		if n.Type == "hard_break" {
+			b.WriteString(closeMarks(&open, nil))
+			b.WriteString("\\\n")
+			continue
+		}
+		if n.Type != "text" {
+			if len(n.Content) > 0 {
+				b.WriteString(renderInline(n.Content))
+			}
+			continue
+		}
+		marks := delimitedMarks(n.Marks)
+		lead, core, trail := splitSpace(n.Text)
+		if core == "" {
+			b.WriteString(n.Text)
+			continue
+		}
+		b.WriteString(closeMarks(&open, marks))
+		b.WriteString(lead)
+		for _, m := range marks[len(open):] {
+			b.WriteString(openDelim(m))
+			open = append(open, m)
+		}
+		if hasMark(n.Marks, "code") {
+			b.WriteString(codeSpan(core))
+		} else {
+			b.WriteString(escapeText(core))
+		}
+		if trail != "" {
+			var next []model.Mark
+			if i+1 < len(nodes) {
+				next = delimitedMarks(nodes[i+1].Marks)
+			}
+			b.WriteString(closeMarks(&open, next))
+			b.WriteString(trail)
+		}
+	}
+	b.WriteString(closeMarks(&open, nil))
+	return b.String()
+}
+
+// mergeText joins adjacent text nodes with identical marks, so escaping sees
+// whole words rather than the fragments the parser split them into.
+func mergeText(nodes []model.Node) []model.Node {
+	merged := make([]model.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if last := len(merged) - 1; last >= 0 && n.Type == "text" && merged[last].Type == "text" &&
+			slices.EqualFunc(n.Marks, merged[last].Marks, sameMark) {
+			merged[last].Text += n.Text
+			continue
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+// delimitedMarks returns the marks that wrap text in delimiters, in a stable
+// outer-to-inner order. Code is handled separately as a code span.
+func delimitedMarks(marks []model.Mark) []model.Mark {
+	order := map[string]int{"link": 0, "strong": 1, "em": 2, "strikethrough": 3}
+	var result []model.Mark
+	for _, m := range marks {
+		if _, ok := order[m.Type]; ok {
+			result = append(result, m)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b model.Mark) int { return order[a.Type] - order[b.Type] })
+	return result
+}
+
+// closeMarks closes open marks until open is a prefix of want.
+func closeMarks(open *[]model.Mark, want []model.Mark) string {
+	keep := 0
+	for keep < len(*open) && keep < len(want) && sameMark((*open)[keep], want[keep]) {
+		keep++
+	}
+	var b strings.Builder
+	for i := len(*open) - 1; i >= keep; i-- {
+		b.WriteString(closeDelim((*open)[i]))
+	}
+	*open = (*open)[:keep]
+	return b.String()
+}
+
+func openDelim(m model.Mark) string {
+	switch m.Type {
+	case "link":
+		return "["
+	case "strong":
+		return "**"
+	case "em":
+		return "*"
+	case "strikethrough":
+		return "~~"
+	}
+	return ""
+}
+
+func closeDelim(m model.Mark) string {
+	if m.Type == "link" {
+		href, _ := m.Attrs["href"].(string)
+		return "](" + href + ")"
+	}
+	return openDelim(m)
+}
+
+func sameMark(a, b model.Mark) bool {
+	return a.Type == b.Type && reflect.DeepEqual(a.Attrs, b.Attrs)
+}
+
+func hasMark(marks []model.Mark, typ string) bool {
+	return slices.ContainsFunc(marks, func(m model.Mark) bool { return m.Type == typ })
+}
+
+func codeSpan(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
+
+func splitSpace(s string) (string, string, string) {
+	core := strings.TrimLeft(s, " \t\n")
+	lead := s[:len(s)-len(core)]
+	trimmed := strings.TrimRight(core, " \t\n")
+	return lead, trimmed, core[len(trimmed):]
+}
+
+// escapeText backslash-escapes characters that would otherwise start inline
+// markdown syntax. Underscores inside words are left alone.
+func escapeText(s string) string {
+	var b strings.Builder
+	for i := range len(s) {
+		c := s[i]
+		switch c {
+		case '\\', '*', '`', '[', ']', '<':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(s)-1 || !isWordByte(s[i-1]) || !isWordByte(s[i+1]) {
+				b.WriteByte('\\')
+			}
+		case '~':
+			if i+1 < len(s) && s[i+1] == '~' {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// escapeLineStarts escapes characters at the start of a paragraph line that
+// would otherwise be read as a heading, quote, list item or rule.
+func escapeLineStarts(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ">"),
+			strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "+ "),
+			strings.HasPrefix(trimmed, "="), trimmed == "-", strings.HasPrefix(trimmed, "---"):
+			lines[i] = indent + "\\" + trimmed
+		default:
+			if n := leadingDigits(trimmed); n > 0 && n < len(trimmed) &&
+				(trimmed[n] == '.' || trimmed[n] == ')') {
+				lines[i] = indent + trimmed[:n] + "\\" + trimmed[n:]
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func leadingDigits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func prefixLines(s, prefix, blankPrefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func plainText(nodes []model.Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(n.Text)
+		b.WriteString(plainText(n.Content))
+	}
+	return b.String()
+}
+
+func isInline(nodes []model.Node) bool {
+	for _, n := range nodes {
+		if n.Type != "text" && n.Type != "hard_break" {
+			return false
+		}
+	}
+	return true
+}
+
+// intAttr reads an integer attribute that may have been decoded from JSON as
+// a float64.
+func intAttr(attrs map[string]any, key string, def int) int {
+	switch v := attrs[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return def
+}
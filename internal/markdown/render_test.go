@@ -0,0 +1,169 @@
+package markdown
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaronsrivastava/substack-cli/internal/model"
+)
+
+// roundTripSources mirrors the inputs of the convert tests, plus combinations
+// that exercise nesting and mark boundaries.
+var roundTripSources = []string{
+	"Some text.\n",
+	"Just a paragraph.\n",
+	"## Sub heading\n",
+	"**bold**\n",
+	"*italic*\n",
+	"~~strike~~\n",
+	"`code`\n",
+	"[click](https://example.com)\n",
+	"> quoted\n",
+	"- a\n- b\n",
+	"1. a\n2. b\n",
+	"```python\nprint('hi')\n```\n",
+	"![A chart](./chart.png \"Quarterly growth\")\n",
+	"[![img](a.png)](https://example.com)\n",
+	"Before ![img](a.png) after\n",
+	"A **bold *and italic*** run with `co*de` and [a **link**](https://x.io).\n",
+	"> quote\n>\n> - nested\n> - list\n",
+	"- item one\n\n  second paragraph\n\n- item two\n",
+	"3. three\n4. four\n",
+	"Literal \\*stars\\* and snake_case and \\# hash.\n",
+	"\\# not a heading\n\n\\- not a list\n\n1\\. not ordered\n",
+	"Line one\nline two\n",
+}
+
+func TestRender_RoundTrip(t *testing.T) {
+	for _, src := range roundTripSources {
+		t.Run(src, func(t *testing.T) {
+			_, want := Convert([]byte(src))
+			rendered := Render(want)
+			_, got := Convert(rendered)
+			if !reflect.DeepEqual(normalize(t, got), normalize(t, want)) {
+				t.Errorf("round trip mismatch\nsource:   %q\nrendered: %q", src, rendered)
+			}
+		})
+	}
+}
+
+func TestRender_RoundTripSample(t *testing.T) {
+	src, err := os.ReadFile("../../testdata/sample.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, want := Convert(src)
+	doc, err := RenderDocument(&Frontmatter{Title: title}, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, gotTitle, got, err := ConvertWithFrontmatter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm == nil || gotTitle != title {
+		t.Errorf("title = %q, want %q", gotTitle, title)
+	}
+	if !reflect.DeepEqual(normalize(t, got), normalize(t, want)) {
+		t.Errorf("round trip mismatch:\n%s", doc)
+	}
+}
+
+func TestRender_Output(t *testing.T) {
+	body := model.DraftBody{Type: "doc", Content: []model.Node{
+		{Type: "heading", Attrs: map[string]any{"level": float64(2)}, Content: []model.Node{{Type: "text", Text: "Hi"}}},
+		{Type: "paragraph", Content: []model.Node{
+			{Type: "text", Text: "plain "},
+			{Type: "text", Text: "bold ", Marks: []model.Mark{{Type: "strong"}}},
+			{Type: "text", Text: "both", Marks: []model.Mark{{Type: "strong"}, {Type: "em"}}},
+		}},
+		{Type: "subscribeWidget"},
+	}}
+	want := "## Hi\n\nplain **bold *both***\n"
+	if got := string(Render(body)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarshalFrontmatter_RoundTrip(t *testing.T) {
+	want := &Frontmatter{
+		Title:    `Quotes "and": colons`,
+		Subtitle: "Sub",
+		Slug:     "quotes",
+		Date:     "2024-01-15",
+		Audience: "only_paid",
+		Tags:     []string{"go", "cli"},
+		Draft:    true,
+		Extra:    map[string]any{"series": "Go"},
+	}
+	data, err := MarshalFrontmatter(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "---\ntitle:") {
+		t.Errorf("unexpected frontmatter:\n%s", data)
+	}
+	got, _, err := ParseFrontmatter(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Warnings = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFrontmatterFromDraft(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	fm := FrontmatterFromDraft(model.DraftResponse{Title: "T", Slug: "t", Audience: "everyone", DraftUpdated: updated})
+	if fm.Title != "T" || fm.Slug != "t" || !fm.Draft {
+		t.Errorf("fm = %+v", fm)
+	}
+	if fm.RemoteUpdatedAt != "2024-05-01T12:30:00Z" {
+		t.Errorf("remote_updated_at = %q", fm.RemoteUpdatedAt)
+	}
+	data, err := MarshalFrontmatter(fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := ParseFrontmatter(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.RemoteUpdatedAt != fm.RemoteUpdatedAt || len(parsed.Warnings) != 0 {
+		t.Errorf("parsed = %+v", parsed)
+	}
+}
+
+// normalize round-trips through JSON so int and float64 attrs compare equal,
+// as they would after a trip through the Substack API.
+func normalize(t *testing.T, body model.DraftBody) model.DraftBody {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out model.DraftBody
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestDecodeBody(t *testing.T) {
+	raw := `{"type":"doc","content":[{"type":"heading","attrs":{"level":3},"content":[{"type":"text","text":"H"}]}]}`
+	body, err := DecodeBody(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(Render(body)); got != "### H\n" {
+		t.Errorf("rendered = %q", got)
+	}
+	if _, err := DecodeBody("not json"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Account struct {
 	Name           string `json:"name"`
@@ -12,8 +15,12 @@ type Account struct {
 }
 
 type AccountStore struct {
+	Version  int       `json:"version"`
 	Active   string    `json:"active"`
 	Accounts []Account `json:"accounts"`
+	// SecretBackend names where account cookies are kept; empty means in this
+	// file, as stores written before backends existed do.
+	SecretBackend string `json:"secret_backend,omitempty"`
 }
 
 type PublishOptions struct {
@@ -44,14 +51,27 @@ type Byline struct {
 }
 
 type DraftRequest struct {
-	Title         string   `json:"draft_title,omitempty"`
-	Subtitle      string   `json:"draft_subtitle,omitempty"`
-	DraftBody     string   `json:"draft_body"`
-	DraftBylines  []Byline `json:"draft_bylines"`
-	Audience      string   `json:"audience,omitempty"`
-	Section       string   `json:"draft_section_id,omitempty"`
-	SectionChosen bool     `json:"section_chosen"`
-	Type          string   `json:"type"`
+	Title             string   `json:"draft_title,omitempty"`
+	Subtitle          string   `json:"draft_subtitle,omitempty"`
+	DraftBody         string   `json:"draft_body"`
+	DraftBylines      []Byline `json:"draft_bylines"`
+	Audience          string   `json:"audience,omitempty"`
+	Section           string   `json:"draft_section_id,omitempty"`
+	SectionChosen     bool     `json:"section_chosen"`
+	Type              string   `json:"type"`
+	Slug              string   `json:"slug,omitempty"`
+	SearchTitle       string   `json:"search_engine_title,omitempty"`
+	SearchDescription string   `json:"search_engine_description,omitempty"`
+	CoverImage        string   `json:"cover_image,omitempty"`
+	CanonicalURL      string   `json:"canonical_url,omitempty"`
+	PostDate          string   `json:"post_date,omitempty"`
+	PodcastURL        string   `json:"podcast_url,omitempty"`
+}
+
+type PostTag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
 
 type Post struct {
@@ -62,9 +82,18 @@ type Post struct {
 	Audience     string    `json:"audience"`
 	Type         string    `json:"type"`
 	DraftCreated time.Time `json:"draft_created_at"`
+	DraftUpdated time.Time `json:"draft_updated_at"`
 	PostDate     string    `json:"post_date"`
 	IsPublished  bool      `json:"is_published"`
 	WordCount    int       `json:"word_count"`
+
+	SectionID         json.Number `json:"section_id,omitempty"`
+	SearchTitle       string      `json:"search_engine_title,omitempty"`
+	SearchDescription string      `json:"search_engine_description,omitempty"`
+	CoverImage        string      `json:"cover_image,omitempty"`
+	CanonicalURL      string      `json:"canonical_url,omitempty"`
+	DraftBody         string      `json:"draft_body,omitempty"`
+	BodyHTML          string      `json:"body_html,omitempty"`
 }
 
 type DraftResponse struct {
@@ -74,12 +103,73 @@ type DraftResponse struct {
 	Slug         string    `json:"slug"`
 	Audience     string    `json:"audience"`
 	DraftCreated time.Time `json:"draft_created_at"`
+	DraftUpdated time.Time `json:"draft_updated_at"`
+	PostDate     string    `json:"post_date"`
 	WordCount    int       `json:"word_count"`
+
+	SectionID         json.Number `json:"draft_section_id,omitempty"`
+	SearchTitle       string      `json:"search_engine_title,omitempty"`
+	SearchDescription string      `json:"search_engine_description,omitempty"`
+	CoverImage        string      `json:"cover_image,omitempty"`
+	CanonicalURL      string      `json:"canonical_url,omitempty"`
+	DraftBody         string      `json:"draft_body,omitempty"`
+}
+
+type ImageUpload struct {
+	URL         string `json:"url"`
+	Width       int    `json:"imageWidth"`
+	Height      int    `json:"imageHeight"`
+	Bytes       int    `json:"bytes"`
+	ContentType string `json:"contentType"`
+}
+
+// PublicationUser is one entry of the publication users endpoint.
+type PublicationUser struct {
+	ID          int                 `json:"id"`
+	Name        string              `json:"name"`
+	Role        string              `json:"role"`
+	Publication *PublicationSummary `json:"publication,omitempty"`
+}
+
+type PublicationSummary struct {
+	ID           int    `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Subdomain    string `json:"subdomain"`
+	CustomDomain string `json:"custom_domain,omitempty"`
+}
+
+// UserProfile is the signed-in user as returned by the profile endpoint,
+// with the publications they belong to.
+type UserProfile struct {
+	ID               int                 `json:"id"`
+	Name             string              `json:"name"`
+	Handle           string              `json:"handle"`
+	PublicationUsers []PublicationMember `json:"publicationUsers"`
+}
+
+type PublicationMember struct {
+	Role        string             `json:"role"`
+	Publication PublicationSummary `json:"publication"`
 }
 
 type Config struct {
+	Version      int    `json:"version"`
 	SendEmail    bool   `json:"send_email"`
 	Audience     string `json:"audience"`
 	Section      string `json:"section"`
 	OutputFormat string `json:"output_format"`
+	Timezone     string `json:"timezone"`
+
+	// Timeout bounds a whole command; RequestTimeout bounds each HTTP request.
+	// Both are Go durations such as "30s"; empty or "0" means no limit.
+	Timeout        string `json:"timeout"`
+	RequestTimeout string `json:"request_timeout"`
+
+	// MaxAttempts caps tries per request, including the first; 0 means the
+	// client default.
+	MaxAttempts int `json:"max_attempts"`
+
+	// Accounts overrides the settings above per account: account name to
+	// config key to value, as given to 'config set --account'.
+	Accounts map[string]map[string]string `json:"accounts,omitempty"`
 }
@@ -0,0 +1,372 @@
+// Package output renders command results as aligned tables, JSON, YAML, CSV
+// or Go templates, so every command can be consumed by scripts the same way.
+//
+// Values are flattened through their JSON encoding: table and CSV columns are
+// JSON field names, which keeps column names identical to the JSON output.
+// Templates see the original Go value, so fields are named as in the structs.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	Table    = "table"
+	JSON     = "json"
+	YAML     = "yaml"
+	CSV      = "csv"
+	Template = "template"
+)
+
+// Formats lists the formats accepted by --output.
+var Formats = []string{Table, JSON, YAML, CSV}
+
+// ErrUnknownColumn is wrapped by errors for --columns names that the result
+// does not have.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// View describes how a command's result is shown as a table.
+type View struct {
+	Columns []string // default table/CSV columns as JSON field names; all fields if empty
+	Empty   string   // printed instead of an empty table
+	RawKeys bool     // label rows and headers by field name, e.g. for config keys
+}
+
+// Printer writes results in one format.
+type Printer struct {
+	W       io.Writer
+	Format  string
+	Columns []string // overrides View.Columns for table and CSV
+	tmpl    *template.Template
+}
+
+// New validates the format and parses the template, if any. A non-empty
+// template selects the template format.
+func New(w io.Writer, format string, columns []string, tmpl string) (*Printer, error) {
+	p := &Printer{W: w, Format: format, Columns: columns}
+	if tmpl != "" {
+		t, err := template.New("output").Funcs(funcs).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
+		}
+		p.Format, p.tmpl = Template, t
+		return p, nil
+	}
+	if p.Format == "" {
+		p.Format = Table
+	}
+	if !slices.Contains(Formats, p.Format) {
+		return nil, fmt.Errorf("unknown output format %q (valid: %s)", format, strings.Join(Formats, ", "))
+	}
+	return p, nil
+}
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+// Human reports whether output is meant for people rather than scripts.
+// Commands print progress and confirmation messages only in this case.
+func (p *Printer) Human() bool {
+	return p.Format == Table
+}
+
+// List writes a slice of results.
+func (p *Printer) List(items any, view View) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("output.List: %T is not a slice", items)
+	}
+	switch p.Format {
+	case JSON:
+		return p.writeJSON(nonNil(v))
+	case YAML:
+		return p.writeYAML(nonNil(v))
+	case Template:
+		for i := range v.Len() {
+			if err := p.execute(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	records := make([]record, v.Len())
+	for i := range v.Len() {
+		r, err := toRecord(v.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		records[i] = r
+	}
+	if p.Format == Table && len(records) == 0 && view.Empty != "" {
+		_, err := fmt.Fprintln(p.W, view.Empty)
+		return err
+	}
+	cols, err := p.columns(view, records)
+	if err != nil {
+		return err
+	}
+	if p.Format == CSV {
+		return p.writeCSV(cols, records)
+	}
+	return p.writeTable(cols, records, view.RawKeys)
+}
+
+// Object writes a single result. Tables show it as one "Field: value" line
+// per column.
+func (p *Printer) Object(item any, view View) error {
+	switch p.Format {
+	case JSON:
+		return p.writeJSON(item)
+	case YAML:
+		return p.writeYAML(item)
+	case Template:
+		return p.execute(item)
+	}
+	r, err := toRecord(item)
+	if err != nil {
+		return err
+	}
+	cols, err := p.columns(view, []record{r})
+	if err != nil {
+		return err
+	}
+	if p.Format == CSV {
+		return p.writeCSV(cols, []record{r})
+	}
+	tw := tabwriter.NewWriter(p.W, 0, 0, 1, ' ', 0)
+	for _, c := range cols {
+		name := c
+		if !view.RawKeys {
+			name = label(c)
+		}
+		fmt.Fprintf(tw, "%s:\t%s\n", name, r.get(c))
+	}
+	return tw.Flush()
+}
+
+// Result writes a confirmation message for people, or item for scripts.
+func (p *Printer) Result(item any, view View, format string, args ...any) error {
+	if p.Human() {
+		_, err := fmt.Fprintf(p.W, format+"\n", args...)
+		return err
+	}
+	return p.Object(item, view)
+}
+
+func (p *Printer) columns(view View, records []record) ([]string, error) {
+	if len(p.Columns) == 0 {
+		if len(view.Columns) > 0 {
+			return view.Columns, nil
+		}
+		return allKeys(records), nil
+	}
+	if len(records) == 0 {
+		return p.Columns, nil
+	}
+	available := allKeys(records)
+	cols := make([]string, len(p.Columns))
+	for i, c := range p.Columns {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if !slices.Contains(available, key) {
+			return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownColumn, c, strings.Join(available, ", "))
+		}
+		cols[i] = key
+	}
+	return cols, nil
+}
+
+func (p *Printer) writeTable(cols []string, records []record, rawKeys bool) error {
+	tw := tabwriter.NewWriter(p.W, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		if rawKeys {
+			headers[i] = c
+		} else {
+			headers[i] = strings.ToUpper(label(c))
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, r := range records {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = r.get(c)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *Printer) writeCSV(cols []string, records []record) error {
+	w := csv.NewWriter(p.W)
+	if err := w.Write(cols); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = r.get(c)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (p *Printer) writeJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(p.W, string(data))
+	return err
+}
+
+// writeYAML goes through JSON so field names match the JSON output, and
+// through yaml.Node so field order is kept.
+func (p *Printer) writeYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if unmarshalErr := yaml.Unmarshal(data, &node); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(p.W)
+	enc.SetIndent(2)
+	if encodeErr := enc.Encode(&node); encodeErr != nil {
+		return encodeErr
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles inherited from the JSON
+// input. The encoder still quotes strings that would read back as another
+// type, such as "123".
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+func (p *Printer) execute(v any) error {
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, v); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err := p.W.Write(buf.Bytes())
+	return err
+}
+
+func nonNil(v reflect.Value) any {
+	if v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return v.Interface()
+}
+
+// record is a JSON object's top-level fields, in encoding order.
+type record []field
+
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+func toRecord(v any) (record, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, tokErr := dec.Token(); tokErr != nil || tok != json.Delim('{') {
+		return nil, errors.New("output: value does not encode to a JSON object")
+	}
+	var r record
+	for dec.More() {
+		tok, tokErr := dec.Token()
+		if tokErr != nil {
+			return nil, tokErr
+		}
+		var raw json.RawMessage
+		if decodeErr := dec.Decode(&raw); decodeErr != nil {
+			return nil, decodeErr
+		}
+		r = append(r, field{key: tok.(string), value: raw})
+	}
+	return r, nil
+}
+
+// allKeys returns every field name in records, in first-seen order. Fields
+// tagged omitempty may be missing from some records.
+func allKeys(records []record) []string {
+	var keys []string
+	for _, r := range records {
+		for _, f := range r {
+			if !slices.Contains(keys, f.key) {
+				keys = append(keys, f.key)
+			}
+		}
+	}
+	return keys
+}
+
+// get formats a field for a table cell: strings unquoted, null empty, and
+// nested values as compact JSON.
+func (r record) get(key string) string {
+	for _, f := range r {
+		if f.key != key {
+			continue
+		}
+		var s string
+		if json.Unmarshal(f.value, &s) == nil {
+			return s
+		}
+		if string(f.value) == "null" {
+			return ""
+		}
+		return string(f.value)
+	}
+	return ""
+}
+
+var acronyms = map[string]string{"id": "ID", "url": "URL", "seo": "SEO", "html": "HTML"}
+
+// label turns a JSON field name like "publication_url" into "Publication URL".
+func label(key string) string {
+	words := strings.Split(key, "_")
+	for i, w := range words {
+		if a, ok := acronyms[w]; ok {
+			words[i] = a
+		} else if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type post struct {
+	ID    int      `json:"id"`
+	Title string   `json:"title"`
+	Slug  string   `json:"slug"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+var posts = []post{
+	{ID: 1, Title: "Hello, world", Slug: "hello"},
+	{ID: 22, Title: "Second", Slug: "second", Tags: []string{"go"}},
+}
+
+func render(t *testing.T, format string, columns []string, tmpl string, fn func(*Printer) error) string {
+	t.Helper()
+	var buf bytes.Buffer
+	p, err := New(&buf, format, columns, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fn(p); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestList(t *testing.T) {
+	view := View{Columns: []string{"id", "title"}, Empty: "No posts."}
+	tests := []struct {
+		name    string
+		format  string
+		columns []string
+		tmpl    string
+		items   []post
+		want    string
+	}{
+		{"table", Table, nil, "", posts, "ID  TITLE\n1   Hello, world\n22  Second\n"},
+		{"table columns", Table, []string{"Slug", "tags"}, "", posts, "SLUG    TAGS\nhello   \nsecond  [\"go\"]\n"},
+		{"table empty", Table, nil, "", nil, "No posts.\n"},
+		{"csv", CSV, nil, "", posts, "id,title\n1,\"Hello, world\"\n22,Second\n"},
+		{"json empty", JSON, nil, "", nil, "[]\n"},
+		{"yaml", YAML, nil, "", posts[:1], "- id: 1\n  title: Hello, world\n  slug: hello\n"},
+		{"template", "", nil, "{{.ID}} {{.Slug}}", posts, "1 hello\n22 second\n"},
+		{"template funcs", "", nil, `{{join .Tags ","}}|{{json .ID}}`, posts[1:], "go|22\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, tt.format, tt.columns, tt.tmpl, func(p *Printer) error {
+				return p.List(tt.items, view)
+			})
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObject(t *testing.T) {
+	got := render(t, Table, nil, "", func(p *Printer) error {
+		return p.Object(struct {
+			ID             int    `json:"id"`
+			PublicationURL string `json:"publication_url"`
+		}{7, "https://x.substack.com"}, View{})
+	})
+	want := "ID:              7\nPublication URL: https://x.substack.com\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = render(t, JSON, nil, "", func(p *Printer) error { return p.Object(posts[0], View{}) })
+	if !strings.Contains(got, `"slug": "hello"`) {
+		t.Errorf("json = %s", got)
+	}
+}
+
+func TestResult(t *testing.T) {
+	human := render(t, Table, nil, "", func(p *Printer) error {
+		return p.Result(posts[0], View{}, "Created: id=%d", 1)
+	})
+	if human != "Created: id=1\n" {
+		t.Errorf("human = %q", human)
+	}
+	script := render(t, CSV, []string{"id"}, "", func(p *Printer) error {
+		return p.Result(posts[0], View{}, "Created: id=%d", 1)
+	})
+	if script != "id\n1\n" {
+		t.Errorf("csv = %q", script)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", nil, ""); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := New(&bytes.Buffer{}, "", nil, "{{.ID"); err == nil {
+		t.Error("expected error for bad template")
+	}
+	p, _ := New(&bytes.Buffer{}, Table, []string{"nope"}, "")
+	err := p.List(posts, View{})
+	if !errors.Is(err, ErrUnknownColumn) || !strings.Contains(err.Error(), "available: id, title, slug, tags") {
+		t.Errorf("err = %v", err)
+	}
+}
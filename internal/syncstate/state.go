@@ -0,0 +1,136 @@
+// Package syncstate tracks which markdown files in a content directory have
+// been synced to which drafts, so repeated syncs only touch what changed.
+package syncstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultFile is the state file name used inside the synced directory.
+const DefaultFile = ".substack-sync.json"
+
+type Entry struct {
+	ID       int       `json:"id"`
+	Hash     string    `json:"hash"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+type State struct {
+	Publication string           `json:"publication"`
+	Files       map[string]Entry `json:"files"`
+}
+
+type Action string
+
+const (
+	Create  Action = "create"
+	Update  Action = "update"
+	Skip    Action = "skip"
+	Missing Action = "missing"
+)
+
+// Change is one planned step. Entry is the existing state, if any; Hash is
+// the current content hash (empty for Missing).
+type Change struct {
+	Path   string
+	Action Action
+	Entry  Entry
+	Hash   string
+}
+
+// Load reads a state file. A missing file yields an empty state.
+func Load(path string) (*State, error) {
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return &State{Files: map[string]Entry{}}, nil
+		}
+		return nil, readErr
+	}
+	var state State
+	if unmarshalErr := json.Unmarshal(data, &state); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	if state.Files == nil {
+		state.Files = map[string]Entry{}
+	}
+	return &state, nil
+}
+
+func Save(state *State, path string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Hash returns a content hash over all parts, in order.
+func Hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = h.Write(p)
+		_, _ = h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// FindMarkdown returns the .md files under dir as slash-separated relative
+// paths, sorted. Hidden files and directories are skipped.
+func FindMarkdown(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	slices.Sort(files)
+	return files, err
+}
+
+// Plan compares current content hashes, keyed by relative path, against the
+// recorded state. Changes are sorted by path.
+func Plan(state *State, hashes map[string]string) []Change {
+	changes := make([]Change, 0, len(hashes))
+	for path, hash := range hashes {
+		entry, ok := state.Files[path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: path, Action: Create, Hash: hash})
+		case entry.Hash != hash:
+			changes = append(changes, Change{Path: path, Action: Update, Entry: entry, Hash: hash})
+		default:
+			changes = append(changes, Change{Path: path, Action: Skip, Entry: entry, Hash: hash})
+		}
+	}
+	for path, entry := range state.Files {
+		if _, ok := hashes[path]; !ok {
+			changes = append(changes, Change{Path: path, Action: Missing, Entry: entry})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	return changes
+}
@@ -0,0 +1,102 @@
+package syncstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	state := &State{Files: map[string]Entry{
+		"same.md":    {ID: 1, Hash: "h1"},
+		"changed.md": {ID: 2, Hash: "old"},
+		"gone.md":    {ID: 3, Hash: "h3"},
+	}}
+	hashes := map[string]string{
+		"same.md":    "h1",
+		"changed.md": "new",
+		"new.md":     "h4",
+	}
+	changes := Plan(state, hashes)
+
+	want := []struct {
+		path   string
+		action Action
+		id     int
+	}{
+		{"changed.md", Update, 2},
+		{"gone.md", Missing, 3},
+		{"new.md", Create, 0},
+		{"same.md", Skip, 1},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Path != w.path || c.Action != w.action || c.Entry.ID != w.id {
+			t.Errorf("change %d = %+v, want %+v", i, c, w)
+		}
+	}
+	if changes[0].Hash != "new" {
+		t.Errorf("update hash = %q, want new", changes[0].Hash)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	state, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Files) != 0 {
+		t.Fatalf("expected empty state, got %+v", state)
+	}
+	state.Publication = "https://x.substack.com"
+	state.Files["a.md"] = Entry{ID: 7, Hash: Hash([]byte("a"))}
+	if saveErr := Save(state, path); saveErr != nil {
+		t.Fatal(saveErr)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Publication != state.Publication || loaded.Files["a.md"].ID != 7 {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash([]byte("ab"), []byte("c")) == Hash([]byte("a"), []byte("bc")) {
+		t.Error("hash should depend on part boundaries")
+	}
+	if Hash([]byte("x")) != Hash([]byte("x")) {
+		t.Error("hash should be deterministic")
+	}
+}
+
+func TestFindMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"b.md", "a.MD", "sub/c.md", "sub/img.png", ".hidden/d.md", ".e.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := FindMarkdown(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.MD", "b.md", "sub/c.md"}
+	if len(files) != len(want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files = %v, want %v", files, want)
+		}
+	}
+}